@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listCategory string
+	exportFormat string
+	jsonOutput   bool
+)
+
+var commandsCmd = &cobra.Command{
+	Use:   "commands",
+	Short: "List, search and export commands without starting the TUI",
+}
+
+var commandsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all commands, optionally of one category",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		registry := loadRegistry()
+		cmds := registry.GetAll()
+		if listCategory != "" {
+			cmds = registry.GetByCategory(listCategory)
+		}
+		printCommands(cmds)
+	},
+}
+
+var commandsSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search commands by template, description, category and tags",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		registry := loadRegistry()
+		cmds := registry.Search(strings.Join(args, " "))
+		if listCategory != "" {
+			cmds = filterCategory(cmds, listCategory)
+		}
+		printCommands(cmds)
+	},
+}
+
+var commandsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export commands as YAML, JSON or Markdown",
+	Long: `Export the merged commands and runbooks to stdout. YAML and JSON output
+use the config file format, so an export can be edited and loaded with --config.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		registry := loadRegistry()
+		format := exportFormat
+		if jsonOutput {
+			format = "json"
+		}
+
+		config := commands.Config{
+			Commands: registry.GetAll(),
+			Runbooks: registry.GetRunbooks(),
+		}
+		if listCategory != "" {
+			config.Commands = registry.GetByCategory(listCategory)
+			config.Runbooks = nil
+			for _, rb := range registry.GetRunbooks() {
+				if strings.EqualFold(rb.Category, listCategory) {
+					config.Runbooks = append(config.Runbooks, rb)
+				}
+			}
+		}
+
+		var out []byte
+		var err error
+		switch format {
+		case "yaml", "yml":
+			out, err = commands.MarshalYAML(config)
+		case "json":
+			out, err = json.MarshalIndent(config, "", "  ")
+			out = append(out, '\n')
+		case "markdown", "md":
+			out = []byte(commandsMarkdown(config.Commands))
+		default:
+			err = fmt.Errorf("unsupported format %q (use yaml, json or markdown)", format)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(out)
+	},
+}
+
+// loadRegistry builds the registry from the embedded packs and all config layers,
+// printing load errors as warnings
+func loadRegistry() *commands.Registry {
+	registry := commands.NewRegistry()
+	for _, err := range registry.LoadLayers(commands.DiscoverLayers(configPath)) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return registry
+}
+
+// filterCategory returns the commands of one category
+func filterCategory(cmds []commands.Command, category string) []commands.Command {
+	var matches []commands.Command
+	for _, cmd := range cmds {
+		if strings.EqualFold(cmd.Category, category) {
+			matches = append(matches, cmd)
+		}
+	}
+	return matches
+}
+
+// printCommands prints commands as a table, or as JSON with --json
+func printCommands(cmds []commands.Command) {
+	if jsonOutput {
+		if cmds == nil {
+			cmds = []commands.Command{}
+		}
+		out, err := json.MarshalIndent(cmds, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	if len(cmds) == 0 {
+		fmt.Fprintln(os.Stderr, "No matching commands.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CATEGORY\tTEMPLATE\tDESCRIPTION")
+	for _, cmd := range cmds {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", cmd.Category, cmd.Template, cmd.Description)
+	}
+	w.Flush()
+}
+
+// commandsMarkdown renders commands as one Markdown table per category
+func commandsMarkdown(cmds []commands.Command) string {
+	byCategory := make(map[string][]commands.Command)
+	var categories []string
+	for _, cmd := range cmds {
+		category := strings.ToLower(cmd.Category)
+		if category == "" {
+			category = "uncategorized"
+		}
+		if _, ok := byCategory[category]; !ok {
+			categories = append(categories, category)
+		}
+		byCategory[category] = append(byCategory[category], cmd)
+	}
+	sort.Strings(categories)
+
+	escape := strings.NewReplacer("|", `\|`)
+	var sb strings.Builder
+	sb.WriteString("# archiTerm Commands\n")
+	for _, category := range categories {
+		fmt.Fprintf(&sb, "\n## %s\n\n", category)
+		sb.WriteString("| Command | Description |\n")
+		sb.WriteString("|---------|-------------|\n")
+		for _, cmd := range byCategory[category] {
+			fmt.Fprintf(&sb, "| %s | %s |\n", codeSpan(escape.Replace(cmd.Template)), escape.Replace(cmd.Description))
+		}
+	}
+	return sb.String()
+}
+
+// codeSpan wraps s in a markdown code span, fenced with more backticks than
+// the longest run in s so templates keep their backticks
+func codeSpan(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+func init() {
+	commandsCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "print JSON for scripting")
+	commandsCmd.PersistentFlags().StringVar(&listCategory, "category", "", "only include commands of this category")
+	commandsExportCmd.Flags().StringVarP(&exportFormat, "format", "f", "yaml", "output format (yaml, json, markdown)")
+	commandsCmd.AddCommand(commandsListCmd)
+	commandsCmd.AddCommand(commandsSearchCmd)
+	commandsCmd.AddCommand(commandsExportCmd)
+	rootCmd.AddCommand(commandsCmd)
+}
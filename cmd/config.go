@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var showResolved bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the config layers, or the merged result with --resolved",
+	Long: `Show the configuration files archiTerm reads, in order of precedence
+(later layers override earlier ones):
+
+  embedded → system → system conf.d → user → user conf.d → project → --config
+
+With --resolved, print the merged commands, runbooks and danger rules
+with the layer each entry came from.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !showResolved {
+			printLayers(commands.DiscoverLayers(configPath))
+			return
+		}
+
+		out, err := resolvedYAML(loadRegistry())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(string(out))
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Check config files for mistakes",
+	Long: `Check config files for syntax errors, unknown fields, empty or duplicate
+templates, unknown categories, bad placeholder declarations, bad runbooks
+and danger rules, and unsupported file extensions.
+
+Without arguments, every config layer that exists is checked. Problems are
+printed as file:line:column: message and the command exits non-zero if
+any are found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		paths := args
+		if len(paths) == 0 {
+			for _, layer := range commands.DiscoverLayers(configPath) {
+				if layer.Exists {
+					paths = append(paths, layer.Path)
+				}
+			}
+		}
+		if len(paths) == 0 {
+			fmt.Println("No config files found.")
+			return
+		}
+
+		problems := commands.ValidateFiles(paths)
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		if len(problems) > 0 {
+			fmt.Fprintf(os.Stderr, "\n✗ %d problem(s) in %d file(s)\n", len(problems), len(paths))
+			os.Exit(1)
+		}
+		fmt.Printf("✓ %d file(s) OK\n", len(paths))
+	},
+}
+
+// printLayers lists the config layers and whether each file exists
+func printLayers(layers []commands.Layer) {
+	fmt.Println("Config layers (lowest to highest precedence):")
+	fmt.Println()
+	fmt.Printf("  ✓ %-14s %s\n", commands.LayerEmbedded, "built-in command packs")
+	for _, layer := range layers {
+		mark := "✗"
+		if layer.Exists {
+			mark = "✓"
+		}
+		fmt.Printf("  %s %-14s %s\n", mark, layer.Name, layer.Path)
+	}
+	fmt.Println()
+	fmt.Println("Usage: architerm config show --resolved")
+}
+
+// resolvedYAML renders the merged registry as YAML, annotating every entry with its source
+func resolvedYAML(registry *commands.Registry) ([]byte, error) {
+	resolved := struct {
+		Commands    []commands.Command        `yaml:"commands"`
+		Runbooks    []commands.Runbook        `yaml:"runbooks,omitempty"`
+		DangerRules []commands.DangerRule     `yaml:"danger_rules,omitempty"`
+		Disable     []string                  `yaml:"disable,omitempty"`
+		History     *commands.HistoryConfig   `yaml:"history,omitempty"`
+		Frecency    *commands.FrecencyConfig  `yaml:"frecency,omitempty"`
+		Execution   *commands.ExecutionConfig `yaml:"execution,omitempty"`
+		Favorites   []string                  `yaml:"favorites,omitempty"`
+		Sets        []commands.FavoriteSet    `yaml:"favorite_sets,omitempty"`
+		EnvProfiles []commands.EnvProfile     `yaml:"env_profiles,omitempty"`
+		Targets     []commands.TargetConfig   `yaml:"targets,omitempty"`
+	}{
+		Commands:    registry.GetAll(),
+		Runbooks:    registry.GetRunbooks(),
+		DangerRules: registry.GetDangerRules(),
+		Favorites:   registry.GetFavorites(),
+		Sets:        registry.GetFavoriteSets()[1:],
+		EnvProfiles: registry.GetEnvProfiles(),
+		Targets:     registry.GetTargets(),
+	}
+	if h := registry.GetHistoryConfig(); h != (commands.HistoryConfig{}) {
+		resolved.History = &h
+	}
+	if f := registry.GetFrecencyConfig(); f != (commands.FrecencyConfig{}) {
+		resolved.Frecency = &f
+	}
+	if e := registry.GetExecutionConfig(); e != (commands.ExecutionConfig{}) {
+		resolved.Execution = &e
+	}
+	for _, d := range registry.GetDisabled() {
+		resolved.Disable = append(resolved.Disable, d.Key)
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(resolved); err != nil {
+		return nil, err
+	}
+
+	// Annotate sequence items with the layer they came from
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, items := doc.Content[i], doc.Content[i+1]
+		for j, item := range items.Content {
+			switch key.Value {
+			case "commands":
+				item.HeadComment = "source: " + resolved.Commands[j].Source
+			case "runbooks":
+				item.HeadComment = "source: " + resolved.Runbooks[j].Source
+			case "danger_rules":
+				item.HeadComment = "source: " + resolved.DangerRules[j].Source
+			case "favorites":
+				item.LineComment = "source: " + registry.FavoriteSource(resolved.Favorites[j])
+			case "favorite_sets":
+				item.HeadComment = "source: " + resolved.Sets[j].Source
+			case "env_profiles":
+				item.HeadComment = "source: " + resolved.EnvProfiles[j].Source
+			case "targets":
+				item.HeadComment = "source: " + resolved.Targets[j].Source
+			case "disable":
+				d := registry.GetDisabled()[j]
+				item.LineComment = fmt.Sprintf("by %s (%d removed)", d.Source, d.Count)
+			}
+		}
+	}
+
+	return commands.MarshalYAML(&doc)
+}
+
+func init() {
+	configShowCmd.Flags().BoolVar(&showResolved, "resolved", false, "print the merged configuration with the source of each entry")
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/duladissa/architerm/internal/frecency"
+	"github.com/duladissa/architerm/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	frecencyAll    bool
+	frecencyGlobal bool
+)
+
+var frecencyCmd = &cobra.Command{
+	Use:   "frecency",
+	Short: "Inspect or reset the usage stats used to rank suggestions",
+	Long: `archiTerm learns which commands you use and ranks them higher in
+suggestions and ghost text. Each use adds one to a template's score, and the
+score halves every week it is not used (frecency = frequency + recency).
+
+Stats are kept globally and, with "frecency: {scope: directory}" or
+"scope: repo" in config, per working directory or git repository.`,
+}
+
+var frecencyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the learned usage stats for the current scope",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		store, scope := openFrecency()
+		now := time.Now()
+
+		scopes := []string{scope}
+		if frecencyAll {
+			scopes = append([]string{frecency.GlobalScope}, store.Scopes()...)
+		}
+
+		if jsonOutput {
+			out := make(map[string][]frecency.TemplateStat)
+			for _, s := range scopes {
+				stats := store.Stats(s, now)
+				if stats == nil {
+					stats = []frecency.TemplateStat{}
+				}
+				out[scopeName(s)] = stats
+			}
+			data, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		for i, s := range scopes {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("Scope: %s\n\n", scopeName(s))
+			stats := store.Stats(s, now)
+			if len(stats) == 0 {
+				fmt.Println("  No usage recorded.")
+				continue
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SCORE\tUSES\tLAST USED\tTEMPLATE")
+			for _, stat := range stats {
+				fmt.Fprintf(w, "%.2f\t%d\t%s\t%s\n", stat.Score, stat.Count, stat.Last.Local().Format("2006-01-02 15:04"), stat.Template)
+			}
+			w.Flush()
+		}
+	},
+}
+
+var frecencyResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Forget the learned usage stats for the current scope",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		store, scope := openFrecency()
+
+		var err error
+		if frecencyAll {
+			err = store.ResetAll()
+		} else {
+			err = store.Reset(scope)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if frecencyAll {
+			fmt.Println("✓ Reset all usage stats")
+		} else {
+			fmt.Printf("✓ Reset usage stats for %s\n", scopeName(scope))
+		}
+	},
+}
+
+// openFrecency opens the frecency file and returns it with the scope of the
+// working directory, as configured (or global with --global)
+func openFrecency() (*frecency.Store, string) {
+	store, err := frecency.Open(frecency.DefaultPath(history.GetDataDir()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if frecencyGlobal {
+		return store, frecency.GlobalScope
+	}
+	cwd, _ := os.Getwd()
+	mode := loadRegistry().GetFrecencyConfig().GetScope()
+	return store, frecency.ScopeFor(cwd, mode)
+}
+
+// scopeName describes a scope for display
+func scopeName(scope string) string {
+	if scope == frecency.GlobalScope {
+		return "global"
+	}
+	return scope
+}
+
+func init() {
+	frecencyCmd.PersistentFlags().BoolVar(&frecencyGlobal, "global", false, "use the global scope instead of the configured directory or repo scope")
+	frecencyShowCmd.Flags().BoolVar(&frecencyAll, "all", false, "show every scope")
+	frecencyShowCmd.Flags().BoolVar(&jsonOutput, "json", false, "print JSON for scripting")
+	frecencyResetCmd.Flags().BoolVar(&frecencyAll, "all", false, "reset every scope")
+	frecencyCmd.AddCommand(frecencyShowCmd)
+	frecencyCmd.AddCommand(frecencyResetCmd)
+	rootCmd.AddCommand(frecencyCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// shellScripts holds the key binding scripts printed by 'architerm init'.
+// Each binds Alt+A to a widget that runs 'architerm pick' with the current
+// command line and replaces it with the chosen command.
+var shellScripts = map[string]string{
+	"bash": `# archiTerm key binding for bash (4.0+)
+# Add to ~/.bashrc:  eval "$(architerm init bash)"
+__architerm_pick() {
+  local cmd
+  cmd="$(architerm pick --query "$READLINE_LINE")" || return
+  READLINE_LINE="$cmd"
+  READLINE_POINT=${#READLINE_LINE}
+}
+
+if [[ $- == *i* ]]; then
+  bind -m emacs-standard -x '"\ea": __architerm_pick'
+  bind -m vi-command -x '"\ea": __architerm_pick'
+  bind -m vi-insert -x '"\ea": __architerm_pick'
+fi
+`,
+	"zsh": `# archiTerm key binding for zsh
+# Add to ~/.zshrc:  eval "$(architerm init zsh)"
+__architerm_pick() {
+  local cmd
+  cmd="$(architerm pick --query "$BUFFER" < /dev/tty)"
+  if [[ $? -eq 0 && -n $cmd ]]; then
+    BUFFER=$cmd
+    CURSOR=${#BUFFER}
+  fi
+  zle reset-prompt
+}
+
+zle -N __architerm_pick
+bindkey -M emacs '\ea' __architerm_pick
+bindkey -M viins '\ea' __architerm_pick
+bindkey -M vicmd '\ea' __architerm_pick
+`,
+	"fish": `# archiTerm key binding for fish
+# Add to ~/.config/fish/config.fish:  architerm init fish | source
+function __architerm_pick
+    set -l cmd (architerm pick --query (commandline | string collect))
+    if test $status -eq 0; and test -n "$cmd"
+        commandline --replace -- $cmd
+    end
+    commandline -f repaint
+end
+
+bind \ea __architerm_pick
+if bind -M insert > /dev/null 2>&1
+    bind -M insert \ea __architerm_pick
+end
+`,
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init <bash|zsh|fish>",
+	Short: "Print a shell key binding that inserts a picked command",
+	Long: `Print a script that binds Alt+A in your shell to 'architerm pick'.
+The chosen command replaces the current command line, ready to edit or run.
+
+  bash:  eval "$(architerm init bash)"      # in ~/.bashrc
+  zsh:   eval "$(architerm init zsh)"       # in ~/.zshrc
+  fish:  architerm init fish | source       # in ~/.config/fish/config.fish`,
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Print(shellScripts[args[0]])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
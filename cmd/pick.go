@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/duladissa/architerm/internal/app"
+	"github.com/spf13/cobra"
+)
+
+var pickQuery string
+
+var pickCmd = &cobra.Command{
+	Use:   "pick",
+	Short: "Choose a command and print it instead of running it",
+	Long: `Open a compact suggestion UI on the terminal and print the chosen command,
+with its placeholders filled in, to stdout. Nothing is executed.
+
+The UI draws on stderr, so the output can be captured by a shell:
+
+  cmd=$(architerm pick --query "docker ps")
+
+Exits non-zero if the picker is cancelled. See 'architerm init' for shell
+key bindings that insert the chosen command into the command line.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		applyTheme()
+		picked, err := app.Pick(configPath, pickQuery)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if picked == "" {
+			os.Exit(1)
+		}
+		fmt.Println(picked)
+	},
+}
+
+func init() {
+	pickCmd.Flags().StringVarP(&pickQuery, "query", "q", "", "initial input, such as the current command line")
+	rootCmd.AddCommand(pickCmd)
+}
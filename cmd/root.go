@@ -30,9 +30,7 @@ Features:
   • Cross-platform (Windows, Linux, macOS)`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Set theme before starting app
-		if themeName != "" {
-			theme.SetTheme(themeName)
-		}
+		applyTheme()
 		if err := app.Run(configPath); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -88,9 +86,21 @@ var themePreviewCmd = &cobra.Command{
 	},
 }
 
+// applyTheme sets the theme named by --theme, or loads it from a theme file
+func applyTheme() {
+	if theme.IsThemeFile(themeName) {
+		if err := theme.SetThemeFile(themeName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load theme: %v\n", err)
+			os.Exit(1)
+		}
+	} else if themeName != "" {
+		theme.SetTheme(themeName)
+	}
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "path to custom config file (YAML or JSON)")
-	rootCmd.PersistentFlags().StringVarP(&themeName, "theme", "t", "dark", "color theme (dark, light, dracula, nord, gruvbox)")
+	rootCmd.PersistentFlags().StringVarP(&themeName, "theme", "t", "dark", "color theme (dark, light, dracula, nord, gruvbox) or path to a theme JSON file")
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(themesCmd)
 	rootCmd.AddCommand(themePreviewCmd)
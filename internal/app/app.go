@@ -2,31 +2,69 @@ package app
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/duladissa/architerm/internal/autocomplete"
 	"github.com/duladissa/architerm/internal/commands"
 	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/frecency"
 	"github.com/duladissa/architerm/internal/history"
+	"github.com/duladissa/architerm/internal/jobs"
+	"github.com/duladissa/architerm/internal/provider"
+	"github.com/duladissa/architerm/internal/runbook"
+	"github.com/duladissa/architerm/internal/session"
 	"github.com/duladissa/architerm/internal/theme"
 	"github.com/duladissa/architerm/internal/ui"
+	"github.com/duladissa/architerm/internal/vterm"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // Model represents the application state
 type Model struct {
 	// UI components
-	styles      *ui.Styles
-	layout      *ui.Layout
-	inputPanel  *ui.InputPanel
-	suggestions *ui.SuggestionsPanel
-	categories  *ui.CategoriesPanel
-	outputPanel *ui.OutputPanel
+	styles         *ui.Styles
+	layout         *ui.Layout
+	inputPanel     *ui.InputPanel
+	suggestions    *ui.SuggestionsPanel
+	categories     *ui.CategoriesPanel
+	favoritesPanel *ui.FavoritesPanel
+	outputPanel    *ui.OutputPanel
+	form           *ui.PlaceholderForm // Non-nil while collecting placeholder values
+	formSubmit     func(*ui.PlaceholderForm) (tea.Model, tea.Cmd)
+
+	confirm       *ui.ConfirmDialog // Non-nil while confirming a destructive command
+	confirmRun    func() tea.Cmd
+	confirmCancel func()
+
+	runbookPicker *ui.RunbookPicker // Non-nil while choosing a runbook
+	runbookPanel  *ui.RunbookPanel
+
+	historySearch *ui.HistorySearch // Non-nil while searching history (Ctrl+R)
+
+	profilePicker  *ui.ChoicePicker // Non-nil while choosing an env profile (Ctrl+E)
+	profileChoices []commands.EnvProfile
+
+	targetPicker *ui.ChoicePicker // Non-nil while choosing an execution target (Ctrl+X)
 
 	// Core components
 	registry   *commands.Registry
 	engine     *autocomplete.Engine
 	executor   *executor.Executor
 	history    *history.History
+	resolver   *provider.Resolver
+	sessionID  string
+
+	// session holds the working directory and environment changed by
+	// built-ins such as cd and export, and the active env profile
+	session *session.Session
+
+	// target is the container or remote host commands run on; nil means locally
+	target     *commands.TargetConfig
+	execTarget executor.Target
+
+	// frecency ranks suggestions by usage; nil if turned off
+	frecency      *frecency.Store
+	frecencyScope string
 
 	// State
 	width      int
@@ -34,11 +72,46 @@ type Model struct {
 	status     string
 	isRunning  bool
 	configPath string
+
+	// configStamp fingerprints the loaded config files for live reload
+	configStamp string
+
+	// activeTemplate is the registry command the input was built from, if any
+	activeTemplate *commands.Command
+
+	// run is the active runbook execution, if any
+	run *runbook.Run
+
+	// jobs are the commands run from the input; outputJob is the one shown
+	// in the output panel (0 while it shows something else)
+	jobs        *jobs.Manager
+	jobsPanel   *ui.JobsPanel
+	outputJob   int
+	jobsTicking bool
+	showJobs    bool // Show the jobs panel in place of the technologies (Ctrl+G)
+
+	// showFavorites shows the favorites panel in place of the technologies (Ctrl+G)
+	showFavorites bool
+
+	// terminal is the command running in the terminal pane (Alt+Enter), if any
+	terminal *vterm.Session
+
+	// runInTerminal runs the command being submitted in the terminal pane
+	runInTerminal bool
+
+	// stopping quits once the cancelled jobs have stopped
+	stopping bool
+
+	// pickMode returns the chosen command instead of running it (architerm pick)
+	pickMode bool
+	picked   string
+	quitting bool
 }
 
 // CommandResultMsg is sent when a command finishes executing
 type CommandResultMsg struct {
 	Result *executor.Result
+	Job    int // 0 for commands run on the terminal
 }
 
 // NewModel creates a new application model
@@ -46,56 +119,51 @@ func NewModel(configPath string) *Model {
 	styles := ui.DefaultStyles()
 	
 	m := &Model{
-		styles:      styles,
-		layout:      ui.NewLayout(styles),
-		inputPanel:  ui.NewInputPanel(styles),
-		suggestions: ui.NewSuggestionsPanel(styles),
-		categories:  ui.NewCategoriesPanel(styles),
-		outputPanel: ui.NewOutputPanel(styles),
-		registry:    commands.NewRegistry(),
-		engine:      autocomplete.NewEngine(),
-		executor:    executor.NewExecutor(),
-		history:     history.NewHistory(100),
-		width:       80,
-		height:      24,
-		status:      "",
-		isRunning:   false,
-		configPath:  configPath,
-	}
-
-	// Load custom config if provided
-	if configPath != "" {
-		customCmds, err := commands.LoadConfig(configPath)
-		if err != nil {
-			m.status = fmt.Sprintf("Config error: %v", err)
-		} else {
-			m.registry.AddCommands(customCmds)
-		}
+		styles:         styles,
+		layout:         ui.NewLayout(styles),
+		inputPanel:     ui.NewInputPanel(styles),
+		suggestions:    ui.NewSuggestionsPanel(styles),
+		categories:     ui.NewCategoriesPanel(styles),
+		favoritesPanel: ui.NewFavoritesPanel(styles),
+		outputPanel:    ui.NewOutputPanel(styles),
+		runbookPanel:   ui.NewRunbookPanel(styles),
+		jobsPanel:      ui.NewJobsPanel(styles),
+		jobs:           jobs.NewManager(),
+		executor:       executor.NewExecutor(),
+		history:        history.NewHistory(history.DefaultMaxEntries),
+		resolver:       provider.NewResolver(),
+		sessionID:      history.NewSessionID(),
+		session:        session.New(),
+		width:          80,
+		height:         24,
+		status:         "",
+		isRunning:      false,
+		configPath:     configPath,
 	}
 
-	// Try loading user config from default location
-	userCmds, _ := commands.LoadUserConfig()
-	if len(userCmds) > 0 {
-		m.registry.AddCommands(userCmds)
+	// Apply config layers (system, user, project, --config) over the embedded packs,
+	// then populate the autocomplete engine and categories from the registry
+	if errs := m.loadRegistry(); len(errs) > 0 {
+		m.status = configErrorStatus(errs)
 	}
 
-	// Populate autocomplete engine
-	for _, cmd := range m.registry.GetAll() {
-		m.engine.AddCommand(cmd.Template, cmd.Description)
+	// Load persistent history using the configured limits
+	if err := m.openHistory(); err != nil && m.status == "" {
+		m.status = fmt.Sprintf("History error: %v", err)
+	}
+	if err := m.openFrecency(); err != nil && m.status == "" {
+		m.status = fmt.Sprintf("Frecency error: %v", err)
 	}
-
-	// Set supported categories from registry
-	m.categories.SetCategories(m.registry.GetCategories())
 
 	// Initialize suggestions
-	m.updateSuggestions()
+	m.sessionChanged()
 
 	return m
 }
 
 // Init implements tea.Model
 func (m *Model) Init() tea.Cmd {
-	return nil
+	return watchConfig()
 }
 
 // Update implements tea.Model
@@ -114,11 +182,44 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case CommandResultMsg:
+		if msg.Job != 0 {
+			m.handleJobResult(msg.Job, msg.Result)
+			if m.stopping && m.jobs.Running() == 0 {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
 		m.isRunning = false
 		m.status = ""
-		fullText := executor.FormatResult(msg.Result)
+		m.recordHistory(msg.Result)
 		// Add as entry for easy copying
-		m.outputPanel.AddEntry(msg.Result.Command, msg.Result.Output, fullText)
+		m.outputPanel.FinishLive("", msg.Result)
+		return m, nil
+
+	case OutputChunkMsg:
+		return m, m.handleOutputChunk(msg)
+
+	case JobsTickMsg:
+		return m, m.handleJobsTick()
+
+	case TerminalUpdateMsg:
+		return m, waitForTerminal(msg.session)
+
+	case TerminalExitMsg:
+		m.handleTerminalExit(msg.session)
+		return m, nil
+
+	case ReloadTickMsg:
+		m.checkConfig()
+		return m, watchConfig()
+
+	case RunbookStepMsg:
+		return m, m.handleRunbookStep(msg.Result)
+
+	case CandidatesMsg:
+		if m.form != nil {
+			m.form.SetCandidates(msg.Field, msg.Key, msg.Values, msg.Err)
+		}
 		return m, nil
 	}
 
@@ -174,48 +275,132 @@ func (m *Model) handleMouseEvent(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 
 // handleKeyPress handles keyboard input
 func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.terminal != nil && m.outputPanel.TerminalFocused {
+		return m.handleTerminalKey(msg)
+	}
+	if m.confirm != nil {
+		return m.handleConfirmKey(msg)
+	}
+	if m.form != nil {
+		return m.handleFormKey(msg)
+	}
+	if m.runbookPicker != nil {
+		return m.handleRunbookPickerKey(msg)
+	}
+	if m.profilePicker != nil {
+		return m.handleProfilePickerKey(msg)
+	}
+	if m.targetPicker != nil {
+		return m.handleTargetPickerKey(msg)
+	}
+	if m.historySearch != nil {
+		return m.handleHistorySearchKey(msg)
+	}
+	if m.jobsPanel.Focused {
+		return m.handleJobsKey(msg)
+	}
+	if m.run != nil {
+		if model, cmd, handled := m.handleRunbookKey(msg); handled {
+			return model, cmd
+		}
+	}
+	if m.pickMode {
+		if model, cmd, handled := m.handlePickKey(msg); handled {
+			return model, cmd
+		}
+	}
+
+	// Alt+1-9 inserts a command from the favorites panel
+	if msg.Alt && msg.Type == tea.KeyRunes && len(msg.Runes) == 1 && m.showFavorites && m.run == nil {
+		if r := msg.Runes[0]; r >= '1' && r <= '9' {
+			return m.insertFavorite(int(r - '0'))
+		}
+	}
+
 	// Check for Shift+Arrow keys for output scrolling
 	if msg.Alt {
 		switch msg.Type {
+		case tea.KeyEnter:
+			// Run the input in the terminal pane, or focus the one running
+			if m.terminal != nil {
+				m.focusTerminal()
+				return m, nil
+			}
+			return m.submit(true)
 		case tea.KeyUp:
 			m.outputPanel.ScrollUp()
 			return m, nil
 		case tea.KeyDown:
 			m.outputPanel.ScrollDown()
 			return m, nil
+		case tea.KeyRunes:
+			// Copy one stream of the last output
+			switch string(msg.Runes) {
+			case "o":
+				m.outputPanel.CopyLastStream(false)
+				return m, nil
+			case "e":
+				m.outputPanel.CopyLastStream(true)
+				return m, nil
+			}
 		}
 	}
 
 	switch msg.Type {
 	case tea.KeyCtrlC:
+		if m.stopping {
+			return m, tea.Quit
+		}
+		if m.terminal != nil {
+			m.terminal.Close()
+			m.status = "Command killed"
+			return m, nil
+		}
+		if job := m.jobs.Get(m.outputJob); job != nil && job.Running() {
+			m.cancelJob(job)
+			return m, nil
+		}
 		if m.isRunning {
 			m.executor.Cancel()
-			m.status = "Command cancelled"
+			m.status = "Stopping command..."
 			return m, nil
 		}
-		return m, tea.Quit
+		return m.quit()
 
 	case tea.KeyEsc:
 		m.inputPanel.Clear()
+		m.activeTemplate = nil
 		m.updateSuggestions()
 		return m, nil
 
 	case tea.KeyTab:
+		// Cycle through the placeholders of an accepted template (snippet-style tab stops)
+		if m.inTabStopMode() {
+			if !m.inputPanel.SelectNextPlaceholder() {
+				m.inputPanel.MoveCursorEnd()
+			}
+			return m, nil
+		}
 		// Accept ghost text or selected suggestion
 		if m.inputPanel.GhostText != "" {
 			m.inputPanel.AcceptGhostText()
 		} else if selected := m.suggestions.GetSelected(); selected != nil {
 			m.inputPanel.SetValue(selected.Command)
 		}
+		m.activeTemplate = m.registry.FindByTemplate(m.inputPanel.Value)
 		m.updateSuggestions()
+		m.inputPanel.SelectNextPlaceholder()
 		return m, nil
 
-	case tea.KeyEnter:
-		if m.inputPanel.Value != "" && !m.isRunning {
-			return m.executeCommand()
+	case tea.KeyShiftTab:
+		if m.inTabStopMode() {
+			m.inputPanel.SelectPrevPlaceholder()
 		}
 		return m, nil
 
+	case tea.KeyEnter:
+		return m.submit(false)
+
 	case tea.KeyUp:
 		if len(m.suggestions.Items) > 0 {
 			m.suggestions.MoveUp()
@@ -223,6 +408,7 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// Navigate history
 			if prev := m.history.Previous(); prev != "" {
 				m.inputPanel.SetValue(prev)
+				m.activeTemplate = m.registry.FindByTemplate(prev)
 				m.updateSuggestions()
 			}
 		}
@@ -235,6 +421,7 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// Navigate history
 			if next := m.history.Next(); next != "" {
 				m.inputPanel.SetValue(next)
+				m.activeTemplate = m.registry.FindByTemplate(next)
 				m.updateSuggestions()
 			}
 		}
@@ -281,11 +468,17 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyCtrlL:
+		m.releaseOutput()
 		m.outputPanel.Clear()
 		return m, nil
 
+	case tea.KeyCtrlK:
+		m.toggleJobsPanel()
+		return m, nil
+
 	case tea.KeyCtrlU:
 		m.inputPanel.Clear()
+		m.activeTemplate = nil
 		m.updateSuggestions()
 		return m, nil
 
@@ -304,6 +497,40 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.cycleTheme()
 		return m, nil
 
+	case tea.KeyCtrlF:
+		// Star or unstar the selected suggestion
+		m.toggleFavorite()
+		return m, nil
+
+	case tea.KeyCtrlG:
+		// Show the favorite sets in place of the technologies
+		m.cycleLeftPanel()
+		return m, nil
+
+	case tea.KeyCtrlR:
+		// Search history
+		m.openHistorySearch()
+		return m, nil
+
+	case tea.KeyCtrlO:
+		// Choose a runbook to run
+		if m.isRunning {
+			m.status = "A command is already running"
+			return m, nil
+		}
+		m.openRunbookPicker()
+		return m, nil
+
+	case tea.KeyCtrlE:
+		// Switch the env profile
+		m.openProfilePicker()
+		return m, nil
+
+	case tea.KeyCtrlX:
+		// Switch the execution target
+		m.openTargetPicker()
+		return m, nil
+
 	case tea.KeyRunes:
 		// Filter out mouse escape sequence characters that might leak through
 		// Mouse sequences typically have multiple characters with digits and special chars
@@ -353,21 +580,74 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// executeCommand runs the current input command
+// submit runs the input, first asking for unresolved placeholders. With
+// inTerminal set the command runs in the terminal pane.
+func (m *Model) submit(inTerminal bool) (tea.Model, tea.Cmd) {
+	if m.inputPanel.Value == "" || m.isRunning {
+		return m, nil
+	}
+	m.runInTerminal = inTerminal
+	if placeholders := m.unresolvedPlaceholders(); len(placeholders) > 0 {
+		m.openForm(placeholders)
+		return m, m.loadCandidates()
+	}
+	return m.executeCommand()
+}
+
+// executeCommand runs the current input command, asking for confirmation first
+// if it matches a danger rule
 func (m *Model) executeCommand() (tea.Model, tea.Cmd) {
 	command := m.inputPanel.Value
-	m.history.Add(command)
-	m.history.Reset()
+	if m.activeTemplate != nil {
+		if err := m.activeTemplate.ValidateFilled(command); err != nil {
+			m.status = fmt.Sprintf("Invalid value: %v", err)
+			return m, nil
+		}
+	}
+	if m.pickMode {
+		return m.finishPick(command)
+	}
+	if danger := m.registry.AssessDanger(command, m.activeTemplate); danger.IsDangerous() {
+		m.showConfirm(command, danger, func() tea.Cmd {
+			return m.runCommand(command)
+		}, nil)
+		return m, nil
+	}
+	return m, m.runCommand(command)
+}
+
+// runCommand executes a command and records it in history
+func (m *Model) runCommand(command string) tea.Cmd {
+	if name, args, ok := session.ParseBuiltin(command); ok {
+		m.runBuiltin(command, name, args)
+		return nil
+	}
+
+	template := m.activeTemplate
+	if template == nil {
+		template = m.registry.FindByTemplate(command)
+	}
+	interactive := commands.IsInteractive(command, template)
+
+	m.recordUsage(command)
+	m.history.Start(command)
 	m.inputPanel.Clear()
+	m.activeTemplate = nil
 	m.updateSuggestions()
-	m.isRunning = true
-	m.status = "Running..."
 
-	// Execute command asynchronously
-	return m, func() tea.Msg {
-		result := m.executor.Execute(command)
-		return CommandResultMsg{Result: result}
+	// Commands that need a terminal get it; others run as background jobs, streaming their output
+	if m.runInTerminal {
+		m.runInTerminal = false
+		m.isRunning = true
+		return m.runTerminal(command, m.execOptions(template))
 	}
+	if interactive {
+		m.isRunning = true
+		return m.runInteractive(command, m.execOptions(template), func(result *executor.Result) tea.Msg {
+			return CommandResultMsg{Result: result}
+		})
+	}
+	return m.startJob(command, m.execOptions(template))
 }
 
 // updateSuggestions updates the suggestions based on current input
@@ -381,11 +661,18 @@ func (m *Model) updateSuggestions() {
 	// Update ghost text
 	ghostText := m.engine.GetGhostText(input)
 	m.inputPanel.SetGhostText(ghostText)
+
+	// Keep tab stops in sync with the accepted template
+	m.inputPanel.SetPlaceholders(m.placeholderNames())
 }
 
 // updateLayout updates panel sizes based on terminal size
 func (m *Model) updateLayout() {
 	m.layout.SetSize(m.width, m.height)
+	if m.pickMode {
+		m.updatePickLayout()
+		return
+	}
 	
 	// Left panel (input + suggestions + categories) width
 	leftWidth := m.layout.GetLeftPanelWidth()
@@ -394,11 +681,39 @@ func (m *Model) updateLayout() {
 	m.suggestions.SetHeight(m.layout.GetSuggestionsHeight())
 	m.categories.SetWidth(leftWidth)
 	m.categories.SetHeight(m.layout.GetCategoriesHeight())
+	m.runbookPanel.SetWidth(leftWidth)
+	m.runbookPanel.SetHeight(m.layout.GetCategoriesHeight())
+	m.favoritesPanel.SetWidth(leftWidth)
+	m.favoritesPanel.SetHeight(m.layout.GetCategoriesHeight())
+	m.jobsPanel.SetWidth(leftWidth)
+	m.jobsPanel.SetHeight(m.layout.GetCategoriesHeight())
 	
 	// Right panel (output) width
 	rightWidth := m.layout.GetRightPanelWidth()
 	m.outputPanel.SetWidth(rightWidth)
 	m.outputPanel.SetHeight(m.layout.GetOutputHeight())
+	if m.terminal != nil {
+		m.terminal.Resize(m.outputPanel.TerminalSize())
+	}
+
+	if m.form != nil {
+		m.form.SetWidth(m.layout.ModalWidth())
+	}
+	if m.runbookPicker != nil {
+		m.runbookPicker.SetWidth(m.layout.ModalWidth())
+	}
+	if m.profilePicker != nil {
+		m.profilePicker.SetWidth(m.layout.ModalWidth())
+	}
+	if m.targetPicker != nil {
+		m.targetPicker.SetWidth(m.layout.ModalWidth())
+	}
+	if m.historySearch != nil {
+		m.historySearch.SetWidth(m.layout.ModalWidth())
+	}
+	if m.confirm != nil {
+		m.confirm.SetWidth(m.layout.ModalWidth())
+	}
 }
 
 // refreshStyles recreates all styles with the current theme
@@ -409,6 +724,27 @@ func (m *Model) refreshStyles() {
 	m.suggestions.SetStyles(m.styles)
 	m.categories.SetStyles(m.styles)
 	m.outputPanel.SetStyles(m.styles)
+	m.runbookPanel.SetStyles(m.styles)
+	m.favoritesPanel.SetStyles(m.styles)
+	m.jobsPanel.SetStyles(m.styles)
+	if m.form != nil {
+		m.form.SetStyles(m.styles)
+	}
+	if m.runbookPicker != nil {
+		m.runbookPicker.SetStyles(m.styles)
+	}
+	if m.profilePicker != nil {
+		m.profilePicker.SetStyles(m.styles)
+	}
+	if m.targetPicker != nil {
+		m.targetPicker.SetStyles(m.styles)
+	}
+	if m.historySearch != nil {
+		m.historySearch.SetStyles(m.styles)
+	}
+	if m.confirm != nil {
+		m.confirm.SetStyles(m.styles)
+	}
 }
 
 // cycleTheme switches to the next available theme
@@ -420,12 +756,57 @@ func (m *Model) cycleTheme() {
 
 // View implements tea.Model
 func (m *Model) View() string {
+	if m.pickMode {
+		return m.pickView()
+	}
 	header := m.layout.RenderHeader()
 	input := m.inputPanel.View()
 	suggestions := m.suggestions.View()
 	categories := m.categories.View()
+	if m.run != nil {
+		categories = m.runbookPanel.View()
+	} else if m.jobsPanel.Focused || m.showJobs {
+		m.jobsPanel.Now = time.Now()
+		categories = m.jobsPanel.View()
+	} else if m.showFavorites {
+		categories = m.favoritesPanel.View()
+	}
 	output := m.outputPanel.View()
-	statusBar := m.layout.RenderStatusBar(m.status)
+	status := m.status
+	if running := m.jobsStatus(); running != "" {
+		if status != "" {
+			running += " │ "
+		}
+		status = running + status
+	}
+	dir := "📁 " + session.Short(m.session.Dir, m.width/4)
+	if m.target != nil {
+		dir = m.targetStatus()
+	}
+	if status != "" {
+		dir += " │ "
+	}
+	status = m.profileStatus() + dir + status
+	statusBar := m.layout.RenderStatusBar(status)
+
+	if m.confirm != nil {
+		return m.layout.RenderModal(header, m.confirm.View(), statusBar)
+	}
+	if m.form != nil {
+		return m.layout.RenderModal(header, m.form.View(), statusBar)
+	}
+	if m.runbookPicker != nil {
+		return m.layout.RenderModal(header, m.runbookPicker.View(), statusBar)
+	}
+	if m.profilePicker != nil {
+		return m.layout.RenderModal(header, m.profilePicker.View(), statusBar)
+	}
+	if m.targetPicker != nil {
+		return m.layout.RenderModal(header, m.targetPicker.View(), statusBar)
+	}
+	if m.historySearch != nil {
+		return m.layout.RenderModal(header, m.historySearch.View(), statusBar)
+	}
 
 	return m.layout.Render(header, input, suggestions, categories, output, statusBar)
 }
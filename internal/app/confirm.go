@@ -0,0 +1,75 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/ui"
+)
+
+// showConfirm asks before running a destructive command. run is called once the
+// user confirms, cancel (if non-nil) when they back out.
+func (m *Model) showConfirm(command string, danger commands.Danger, run func() tea.Cmd, cancel func()) {
+	m.confirm = ui.NewConfirmDialog(m.styles, command, danger)
+	m.confirm.SetWidth(m.layout.ModalWidth())
+	m.confirmRun = run
+	m.confirmCancel = cancel
+	m.status = "Confirm destructive command"
+}
+
+// closeConfirm hides the confirmation dialog
+func (m *Model) closeConfirm() {
+	m.confirm = nil
+	m.confirmRun = nil
+	m.confirmCancel = nil
+}
+
+// handleConfirmKey handles keyboard input while the confirmation dialog is open
+func (m *Model) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		cancel := m.confirmCancel
+		m.closeConfirm()
+		if cancel != nil {
+			cancel()
+		}
+		m.status = "Cancelled"
+		return m, nil
+
+	case tea.KeyEnter:
+		if !m.confirm.RequiresTyping() || !m.confirm.Confirmed() {
+			return m, nil
+		}
+		run := m.confirmRun
+		m.closeConfirm()
+		return m, run()
+	}
+
+	if !m.confirm.RequiresTyping() {
+		if msg.Type == tea.KeyRunes {
+			switch string(msg.Runes) {
+			case "y", "Y":
+				run := m.confirmRun
+				m.closeConfirm()
+				return m, run()
+			case "n", "N":
+				return m.handleConfirmKey(tea.KeyMsg{Type: tea.KeyEsc})
+			}
+		}
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyBackspace:
+		m.confirm.DeleteChar()
+	case tea.KeySpace:
+		m.confirm.InsertChar(' ')
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r < 32 || r == 127 {
+				continue
+			}
+			m.confirm.InsertChar(r)
+		}
+	}
+	return m, nil
+}
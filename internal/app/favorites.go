@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/commands"
+)
+
+// toggleFavorite stars or unstars the selected suggestion (or the template the
+// input was built from) in the user's favorites file
+func (m *Model) toggleFavorite() {
+	template := ""
+	if selected := m.suggestions.GetSelected(); selected != nil {
+		template = selected.Command
+	} else if m.activeTemplate != nil {
+		template = m.activeTemplate.Template
+	}
+	if template == "" {
+		m.status = "Select a suggestion to star it"
+		return
+	}
+
+	path := commands.GetFavoritesPath()
+	if source := m.registry.FavoriteSource(template); source != "" && source != path {
+		m.status = fmt.Sprintf("Favorite is set in %s", source)
+		return
+	}
+
+	added, err := commands.ToggleFavorite(path, template)
+	if err != nil {
+		m.status = fmt.Sprintf("Favorite error: %v", err)
+		return
+	}
+
+	// Apply right away instead of waiting for the config watcher
+	if errs := m.reloadConfig(); len(errs) > 0 {
+		m.status = configErrorStatus(errs)
+		return
+	}
+	m.suggestions.Select(template)
+
+	if added {
+		m.status = "★ Starred " + template
+	} else {
+		m.status = "Unstarred " + template
+	}
+}
+
+// cycleLeftPanel switches the bottom-left panel from the technologies to each
+// favorite set in turn, then the jobs, and back
+func (m *Model) cycleLeftPanel() {
+	switch {
+	case m.showJobs:
+		m.showJobs = false
+	case !m.showFavorites:
+		m.showFavorites = true
+		m.favoritesPanel.Index = 0
+	case m.favoritesPanel.Index < len(m.favoritesPanel.Sets)-1:
+		m.favoritesPanel.Index++
+	default:
+		m.showFavorites = false
+		m.showJobs = true
+	}
+}
+
+// insertFavorite puts the n-th command of the shown favorite set into the input
+func (m *Model) insertFavorite(n int) (tea.Model, tea.Cmd) {
+	command := m.favoritesPanel.Command(n)
+	if command == "" {
+		return m, nil
+	}
+	m.inputPanel.SetValue(command)
+	m.activeTemplate = m.registry.FindByTemplate(command)
+	m.updateSuggestions()
+	m.inputPanel.SelectNextPlaceholder()
+	return m, nil
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/duladissa/architerm/internal/frecency"
+	"github.com/duladissa/architerm/internal/history"
+)
+
+// openFrecency loads the learned command usage, unless turned off in config
+func (m *Model) openFrecency() error {
+	config := m.registry.GetFrecencyConfig()
+	if !config.IsEnabled() {
+		m.frecency = nil
+		return nil
+	}
+	if m.frecency == nil {
+		store, err := frecency.Open(frecency.DefaultPath(history.GetDataDir()))
+		m.frecency = store
+		if err != nil {
+			return err
+		}
+	}
+	m.updateFrecencyScope()
+	return nil
+}
+
+// updateFrecencyScope sets the scope usage is ranked by from the session's
+// working directory
+func (m *Model) updateFrecencyScope() {
+	m.frecencyScope = frecency.ScopeFor(m.session.Dir, m.registry.GetFrecencyConfig().GetScope())
+}
+
+// commandUsage returns the frecency of a template, for ranking suggestions
+func (m *Model) commandUsage(template string) float64 {
+	if m.frecency == nil {
+		return 0
+	}
+	return m.frecency.Score(template, m.frecencyScope, time.Now())
+}
+
+// recordUsage counts a use of the template command was built from. Commands
+// typed without a template are not ranked, as they never appear as suggestions.
+func (m *Model) recordUsage(command string) {
+	if m.frecency == nil {
+		return
+	}
+	template := m.activeTemplate
+	if template == nil {
+		template = m.registry.FindByTemplate(command)
+	}
+	if template == nil {
+		return
+	}
+	if err := m.frecency.Record(template.Template, m.frecencyScope, time.Now()); err != nil {
+		m.status = fmt.Sprintf("Frecency error: %v", err)
+	}
+}
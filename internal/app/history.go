@@ -0,0 +1,42 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/history"
+)
+
+// openHistory applies the history settings from config and loads the
+// history file, unless persistence is turned off
+func (m *Model) openHistory() error {
+	config := m.registry.GetHistoryConfig()
+	m.history.SetMaxSize(config.GetMaxEntries())
+	m.history.SetDedupe(config.Dedupe)
+	if !config.IsPersistent() {
+		return nil
+	}
+	return m.history.Open(history.NewStore(config.GetFile()))
+}
+
+// recordHistory adds a finished command to history with its run details,
+// completing the entry added when it was submitted
+func (m *Model) recordHistory(result *executor.Result) {
+	cwd := result.Dir
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	err := m.history.Record(history.Entry{
+		Command:    result.Command,
+		Time:       result.StartTime,
+		Cwd:        cwd,
+		Target:     result.Target,
+		ExitCode:   result.ExitCode,
+		DurationMs: result.Duration.Milliseconds(),
+		Session:    m.sessionID,
+	})
+	if err != nil {
+		m.status = fmt.Sprintf("History error: %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/executor"
+)
+
+// runInteractive suspends the TUI and runs command on the real terminal with
+// opts, sending the message built by done when it exits and the TUI is back
+func (m *Model) runInteractive(command string, opts executor.Options, done func(*executor.Result) tea.Msg) tea.Cmd {
+	m.status = "Running in the terminal..."
+	m.releaseOutput()
+	start := time.Now()
+	return tea.ExecProcess(executor.InteractiveCommand(command, opts), func(err error) tea.Msg {
+		return done(executor.InteractiveResult(command, opts, start, err))
+	})
+}
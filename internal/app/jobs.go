@@ -0,0 +1,176 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/jobs"
+)
+
+// JobsTickMsg redraws elapsed times while jobs are running
+type JobsTickMsg struct{}
+
+// startJob runs command as a background job, showing its output live
+func (m *Model) startJob(command string, opts executor.Options) tea.Cmd {
+	job, ctx := m.jobs.Add(command, opts.Dir, opts.TargetName())
+	m.jobsPanel.SetJobs(m.jobs.All())
+	m.showJob(job)
+	m.status = fmt.Sprintf("Job [%d] running", job.ID)
+
+	execute := func(onChunk func(executor.Chunk)) *executor.Result {
+		return executor.ExecuteContext(ctx, command, opts, onChunk)
+	}
+	done := func(result *executor.Result) tea.Msg {
+		return CommandResultMsg{Result: result, Job: job.ID}
+	}
+	return tea.Batch(streamCommand(job.ID, execute, done), m.tickJobs())
+}
+
+// showJob brings a job's output into the output panel, following it live if
+// the job is still running
+func (m *Model) showJob(job *jobs.Job) {
+	m.outputJob = job.ID
+	m.jobsPanel.Shown = job.ID
+	if job.Running() {
+		m.outputPanel.StartLive(executor.FormatHeader(job.Command, job.Dir, job.Target), job.Chunks())
+		return
+	}
+	m.outputPanel.AddResult("", job.Result)
+}
+
+// releaseOutput stops showing a job's output, before the output panel is
+// used for something else
+func (m *Model) releaseOutput() {
+	m.outputJob = 0
+	m.jobsPanel.Shown = 0
+}
+
+// handleJobResult records a finished job, updating the output panel if it is
+// showing the job
+func (m *Model) handleJobResult(id int, result *executor.Result) {
+	job := m.jobs.Finish(id, result)
+	m.recordHistory(result)
+	if job == nil {
+		return
+	}
+	if id == m.outputJob {
+		m.outputPanel.FinishLive("", result)
+		m.status = ""
+		return
+	}
+	m.status = fmt.Sprintf("Job [%d] %s", job.ID, job.Status())
+}
+
+// cancelJob asks a running job to stop
+func (m *Model) cancelJob(job *jobs.Job) {
+	if job == nil || !job.Running() {
+		return
+	}
+	job.Cancel()
+	m.status = fmt.Sprintf("Job [%d] stopping...", job.ID)
+}
+
+// quit stops running jobs and exits once they have stopped, so their
+// process groups are not left behind. Quitting again exits at once.
+func (m *Model) quit() (tea.Model, tea.Cmd) {
+	if m.terminal != nil {
+		m.terminal.Close()
+	}
+	if running := m.jobs.Running(); running > 0 && !m.stopping {
+		m.jobs.CancelAll()
+		m.stopping = true
+		m.status = fmt.Sprintf("Stopping %d job(s)... Ctrl+C again to quit now", running)
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+// tickJobs schedules a redraw of elapsed times, unless one is scheduled
+func (m *Model) tickJobs() tea.Cmd {
+	if m.jobsTicking {
+		return nil
+	}
+	m.jobsTicking = true
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return JobsTickMsg{}
+	})
+}
+
+// handleJobsTick keeps ticking while any job runs
+func (m *Model) handleJobsTick() tea.Cmd {
+	m.jobsTicking = false
+	if m.jobs.Running() == 0 {
+		return nil
+	}
+	return m.tickJobs()
+}
+
+// toggleJobsPanel focuses the jobs panel, or leaves it
+func (m *Model) toggleJobsPanel() {
+	if m.jobsPanel.Focused {
+		m.jobsPanel.Focused = false
+		return
+	}
+	m.jobsPanel.SetJobs(m.jobs.All())
+	m.jobsPanel.Select(m.outputJob)
+	m.jobsPanel.Focused = true
+}
+
+// handleJobsKey handles keys while the jobs panel is focused
+func (m *Model) handleJobsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	job := m.jobsPanel.GetSelected()
+
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlK:
+		m.jobsPanel.Focused = false
+	case tea.KeyUp:
+		m.jobsPanel.MoveUp()
+	case tea.KeyDown:
+		m.jobsPanel.MoveDown()
+	case tea.KeyEnter:
+		if job != nil {
+			if m.run != nil || m.terminal != nil {
+				m.status = "The output panel is in use"
+				return m, nil
+			}
+			m.showJob(job)
+		}
+	case tea.KeyCtrlC:
+		m.cancelJob(job)
+	case tea.KeyDelete:
+		m.removeJob(job)
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "x":
+			m.cancelJob(job)
+		case "d":
+			m.removeJob(job)
+		case "D":
+			m.jobs.RemoveFinished()
+			m.jobsPanel.SetJobs(m.jobs.All())
+		}
+	}
+	return m, nil
+}
+
+// removeJob drops a finished job from the list
+func (m *Model) removeJob(job *jobs.Job) {
+	if job == nil {
+		return
+	}
+	if !m.jobs.Remove(job.ID) {
+		m.status = fmt.Sprintf("Job [%d] is running: x to cancel it first", job.ID)
+		return
+	}
+	m.jobsPanel.SetJobs(m.jobs.All())
+}
+
+// jobsStatus summarizes running jobs for the status bar
+func (m *Model) jobsStatus() string {
+	if running := m.jobs.Running(); running > 0 {
+		return fmt.Sprintf("⚙️  %d running", running)
+	}
+	return ""
+}
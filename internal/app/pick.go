@@ -0,0 +1,88 @@
+package app
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pickSuggestionsHeight is the height of the suggestions list in pick mode
+const pickSuggestionsHeight = 10
+
+// handlePickKey handles keys that behave differently when picking a command
+// for the parent shell. handled is false for keys that use the normal handling.
+func (m *Model) handlePickKey(msg tea.KeyMsg) (model tea.Model, cmd tea.Cmd, handled bool) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit, true
+
+	case tea.KeyCtrlO, tea.KeyCtrlL, tea.KeyCtrlY, tea.KeyCtrlB, tea.KeyCtrlK:
+		// Runbooks, jobs and output shortcuts have no use without the output panel
+		return m, nil, true
+
+	case tea.KeyCtrlE, tea.KeyCtrlX:
+		// The picked command runs in the parent shell, with its environment and on its machine
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// finishPick hands the chosen command back instead of running it
+func (m *Model) finishPick(command string) (tea.Model, tea.Cmd) {
+	m.recordUsage(command)
+	m.picked = command
+	m.quitting = true
+	return m, tea.Quit
+}
+
+// updatePickLayout sizes the compact pick UI to the terminal width
+func (m *Model) updatePickLayout() {
+	m.inputPanel.SetWidth(m.width)
+	m.suggestions.SetWidth(m.width)
+	m.suggestions.SetHeight(pickSuggestionsHeight)
+	if m.form != nil {
+		m.form.SetWidth(m.layout.ModalWidth())
+	}
+	if m.historySearch != nil {
+		m.historySearch.SetWidth(m.layout.ModalWidth())
+	}
+}
+
+// pickView renders the compact pick UI
+func (m *Model) pickView() string {
+	if m.quitting {
+		return ""
+	}
+	if m.form != nil {
+		return m.form.View()
+	}
+	if m.historySearch != nil {
+		return m.historySearch.View()
+	}
+	hint := m.styles.StatusKeyHint.Render(" Tab: complete │ ↑↓: select │ Ctrl+R: history │ Enter: insert │ Esc: cancel")
+	return lipgloss.JoinVertical(lipgloss.Left, m.inputPanel.View(), m.suggestions.View(), hint)
+}
+
+// Pick runs a compact command picker on the terminal, drawing on stderr, and
+// returns the chosen command with its placeholders filled in. It returns an
+// empty string if the user cancels.
+func Pick(configPath, query string) (string, error) {
+	// Render for stderr, since stdout is captured by the calling shell
+	lipgloss.SetDefaultRenderer(lipgloss.NewRenderer(os.Stderr))
+
+	model := NewModel(configPath)
+	model.pickMode = true
+	if query != "" {
+		model.inputPanel.SetValue(query)
+		model.activeTemplate = model.registry.FindByTemplate(query)
+		model.updateSuggestions()
+	}
+
+	p := tea.NewProgram(model, tea.WithInputTTY(), tea.WithOutput(os.Stderr))
+	if _, err := p.Run(); err != nil {
+		return "", err
+	}
+	return model.picked, nil
+}
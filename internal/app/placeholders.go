@@ -0,0 +1,164 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/ui"
+)
+
+// CandidatesMsg carries provider results for a placeholder form field
+type CandidatesMsg struct {
+	Field  string
+	Key    string // Resolved provider command
+	Values []string
+	Err    error
+}
+
+// unresolvedPlaceholders returns the placeholders still present in the input
+func (m *Model) unresolvedPlaceholders() []commands.Placeholder {
+	input := m.inputPanel.Value
+	cmd := m.activeTemplate
+	if cmd == nil {
+		cmd = m.registry.FindByTemplate(input)
+	}
+	if cmd == nil {
+		return nil
+	}
+	return commands.PresentPlaceholders(input, cmd.GetPlaceholders())
+}
+
+// placeholderNames returns the placeholder names of the accepted template
+func (m *Model) placeholderNames() []string {
+	if m.activeTemplate == nil {
+		return nil
+	}
+	var names []string
+	for _, p := range m.activeTemplate.GetPlaceholders() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// inTabStopMode returns true while Tab should cycle through template placeholders
+// instead of accepting suggestions: a template with placeholders was accepted and
+// the user has started moving through or editing it
+func (m *Model) inTabStopMode() bool {
+	if m.activeTemplate == nil || len(m.inputPanel.Placeholders) == 0 {
+		return false
+	}
+	return m.inputPanel.HasSelection() || m.inputPanel.Value != m.activeTemplate.Template
+}
+
+// openForm shows the placeholder form for the current input
+func (m *Model) openForm(placeholders []commands.Placeholder) {
+	m.showForm(ui.NewPlaceholderForm(m.styles, m.inputPanel.Value, commands.OrderByDependencies(placeholders)), m.submitCommandForm)
+	m.status = "Fill in placeholders"
+}
+
+// showForm opens a placeholder form; submit is called with the form once all values are valid
+func (m *Model) showForm(form *ui.PlaceholderForm, submit func(*ui.PlaceholderForm) (tea.Model, tea.Cmd)) {
+	m.form = form
+	m.form.SetWidth(m.layout.ModalWidth())
+	m.formSubmit = submit
+}
+
+// submitCommandForm runs the input command with the form values filled in
+func (m *Model) submitCommandForm(form *ui.PlaceholderForm) (tea.Model, tea.Cmd) {
+	m.inputPanel.SetValue(form.Resolve())
+	if m.isRunning {
+		m.status = "A command is already running"
+		return m, nil
+	}
+	return m.executeCommand()
+}
+
+// handleFormKey handles keyboard input while the placeholder form is open
+func (m *Model) handleFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.form = nil
+		m.formSubmit = nil
+		m.status = "Cancelled"
+		return m, nil
+
+	case tea.KeyTab:
+		m.form.NextField()
+		return m, m.loadCandidates()
+
+	case tea.KeyShiftTab:
+		m.form.PrevField()
+		return m, m.loadCandidates()
+
+	case tea.KeyDown:
+		if m.form.HasCandidates() {
+			m.form.MoveCandidateDown()
+			return m, nil
+		}
+		m.form.NextField()
+		return m, m.loadCandidates()
+
+	case tea.KeyUp:
+		if m.form.HasCandidates() {
+			m.form.MoveCandidateUp()
+			return m, nil
+		}
+		m.form.PrevField()
+		return m, m.loadCandidates()
+
+	case tea.KeyEnter:
+		if m.form.AcceptCandidate() && m.form.IsLastField() {
+			return m, nil
+		}
+		if !m.form.IsLastField() {
+			m.form.NextField()
+			return m, m.loadCandidates()
+		}
+		if !m.form.Validate() {
+			return m, nil
+		}
+		form, submit := m.form, m.formSubmit
+		m.form = nil
+		m.formSubmit = nil
+		return submit(form)
+
+	case tea.KeyBackspace:
+		m.form.DeleteChar()
+		return m, nil
+
+	case tea.KeyCtrlU:
+		m.form.ClearField()
+		return m, nil
+
+	case tea.KeySpace:
+		m.form.InsertChar(' ')
+		return m, nil
+
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r < 32 || r == 127 {
+				continue
+			}
+			m.form.InsertChar(r)
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// loadCandidates fetches provider candidates for the focused form field
+func (m *Model) loadCandidates() tea.Cmd {
+	name, command, ttl, ok := m.form.NeedsCandidates()
+	if !ok {
+		return nil
+	}
+	if values, cached := m.resolver.Cached(command); cached {
+		m.form.SetCandidates(name, command, values, nil)
+		return nil
+	}
+	resolver := m.resolver
+	return func() tea.Msg {
+		values, err := resolver.Resolve(command, ttl)
+		return CandidatesMsg{Field: name, Key: command, Values: values, Err: err}
+	}
+}
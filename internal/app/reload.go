@@ -0,0 +1,136 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/autocomplete"
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/theme"
+)
+
+// reloadInterval is how often config files are checked for changes
+const reloadInterval = time.Second
+
+// ReloadTickMsg triggers a check of the config files for changes
+type ReloadTickMsg struct{}
+
+// watchConfig schedules the next config change check
+func watchConfig() tea.Cmd {
+	return tea.Tick(reloadInterval, func(time.Time) tea.Msg {
+		return ReloadTickMsg{}
+	})
+}
+
+// loadRegistry builds the command registry from all config layers and
+// rebuilds the autocomplete engine and categories from it.
+// Layers that fail to load are skipped; their errors, or the validation
+// problems of the loaded files, are returned.
+func (m *Model) loadRegistry() []error {
+	layers := commands.DiscoverLayers(m.configPath)
+	registry := commands.NewRegistry()
+	errs := registry.LoadLayers(layers)
+	if len(errs) == 0 {
+		// Files loaded, but may still contain mistakes such as misspelled fields
+		for _, problem := range commands.ValidateLayers(layers) {
+			errs = append(errs, errors.New(problem.String()))
+		}
+	}
+
+	engine := autocomplete.NewEngine()
+	for _, cmd := range registry.GetAll() {
+		engine.AddCommand(cmd.Template, cmd.Description)
+	}
+	engine.SetUsage(m.commandUsage)
+	engine.SetFavorites(registry.GetFavorites())
+
+	m.registry = registry
+	m.engine = engine
+	m.categories.SetCategories(registry.GetCategories())
+	m.favoritesPanel.SetSets(registry.GetFavoriteSets())
+	m.configStamp = m.configFingerprint()
+	return errs
+}
+
+// configFingerprint summarizes the files that make up the configuration, so
+// edits, new drop-ins and removed files can be detected by comparing stamps
+func (m *Model) configFingerprint() string {
+	var paths []string
+	for _, layer := range commands.DiscoverLayers(m.configPath) {
+		if layer.Exists {
+			paths = append(paths, layer.Path)
+		}
+	}
+	if theme.CurrentThemePath != "" {
+		paths = append(paths, theme.CurrentThemePath)
+	}
+
+	var sb strings.Builder
+	for _, path := range paths {
+		sb.WriteString(path)
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&sb, ":%d:%d", info.ModTime().UnixNano(), info.Size())
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// checkConfig reloads commands and the theme file if any config file changed
+func (m *Model) checkConfig() {
+	stamp := m.configFingerprint()
+	if stamp == m.configStamp {
+		return
+	}
+	m.configStamp = stamp
+
+	var errs []error
+	if path := theme.CurrentThemePath; path != "" {
+		if err := theme.SetThemeFile(path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		} else {
+			m.refreshStyles()
+		}
+	}
+
+	errs = append(errs, m.reloadConfig()...)
+	if len(errs) > 0 {
+		m.status = configErrorStatus(errs)
+		return
+	}
+	if !m.isRunning {
+		m.status = fmt.Sprintf("Config reloaded (%d commands)", len(m.registry.GetAll()))
+	}
+}
+
+// reloadConfig rebuilds the registry and everything derived from it: the
+// execution target, cached provider values, frecency settings, the accepted
+// template and the suggestions
+func (m *Model) reloadConfig() []error {
+	errs := m.loadRegistry()
+	m.refreshTarget()
+	m.resolver.Invalidate()
+	m.resolver.SetOptions(m.execOptions(nil))
+	if err := m.openFrecency(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Keep the accepted template in sync with the new registry
+	if m.activeTemplate != nil {
+		m.activeTemplate = m.registry.FindByTemplate(m.activeTemplate.Template)
+	}
+	m.updateSuggestions()
+	return errs
+}
+
+// configErrorStatus summarizes config errors for the status bar
+func configErrorStatus(errs []error) string {
+	if len(errs) == 1 {
+		return fmt.Sprintf("Config error: %v", errs[0])
+	}
+	return fmt.Sprintf("Config error: %v (+%d more, run 'architerm config validate')", errs[0], len(errs)-1)
+}
@@ -0,0 +1,236 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/runbook"
+	"github.com/duladissa/architerm/internal/ui"
+)
+
+// RunbookStepMsg is sent when a runbook step finishes executing
+type RunbookStepMsg struct {
+	Result *executor.Result
+}
+
+// openRunbookPicker shows the runbook picker
+func (m *Model) openRunbookPicker() {
+	m.runbookPicker = ui.NewRunbookPicker(m.styles, m.registry.GetRunbooks())
+	m.runbookPicker.SetWidth(m.layout.ModalWidth())
+	m.status = "Choose a runbook"
+}
+
+// handleRunbookPickerKey handles keyboard input while the runbook picker is open
+func (m *Model) handleRunbookPickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.runbookPicker = nil
+		m.status = "Cancelled"
+		return m, nil
+
+	case tea.KeyUp:
+		m.runbookPicker.MoveUp()
+		return m, nil
+
+	case tea.KeyDown:
+		m.runbookPicker.MoveDown()
+		return m, nil
+
+	case tea.KeyEnter:
+		selected := m.runbookPicker.GetSelected()
+		if selected == nil {
+			return m, nil
+		}
+		rb := *selected
+		m.runbookPicker = nil
+		if m.isRunning {
+			m.status = "A command is already running"
+			return m, nil
+		}
+
+		inputs := rb.Inputs()
+		if len(inputs) == 0 {
+			return m.startRunbook(rb, nil)
+		}
+		form := ui.NewPlaceholderForm(m.styles, "", commands.OrderByDependencies(inputs))
+		form.Title = "📒 " + rb.Name + " inputs"
+		m.showForm(form, func(form *ui.PlaceholderForm) (tea.Model, tea.Cmd) {
+			return m.startRunbook(rb, form.Values())
+		})
+		m.status = "Fill in runbook inputs"
+		return m, m.loadCandidates()
+	}
+
+	return m, nil
+}
+
+// startRunbook begins executing a runbook with the given input values
+func (m *Model) startRunbook(rb commands.Runbook, inputs map[string]string) (tea.Model, tea.Cmd) {
+	if len(rb.Steps) == 0 {
+		m.status = fmt.Sprintf("Runbook %q has no steps", rb.Name)
+		return m, nil
+	}
+	m.run = runbook.NewRun(rb, inputs)
+	m.runbookPanel.SetRun(m.run)
+	return m, m.advanceRunbook()
+}
+
+// advanceRunbook starts the current runbook step if the run may proceed
+func (m *Model) advanceRunbook() tea.Cmd {
+	run := m.run
+	if run == nil || m.isRunning {
+		return nil
+	}
+	if run.Done() {
+		m.status = fmt.Sprintf("Runbook %q finished", run.Runbook.Name)
+		return nil
+	}
+	if run.Blocked() {
+		m.status = fmt.Sprintf("Step %d failed: r to retry, s to skip", run.Current+1)
+		return nil
+	}
+	if !run.CanAdvance() {
+		m.status = "Runbook paused: p to resume"
+		return nil
+	}
+
+	command, err := run.StepCommand()
+	if err != nil {
+		run.Fail(err.Error())
+		m.status = fmt.Sprintf("Step %d failed: %v", run.Current+1, err)
+		return nil
+	}
+
+	if danger := m.registry.AssessDanger(command, nil); danger.IsDangerous() {
+		m.showConfirm(command, danger, func() tea.Cmd {
+			return m.runRunbookStep(command)
+		}, func() {
+			run.Fail("not confirmed")
+		})
+		return nil
+	}
+	return m.runRunbookStep(command)
+}
+
+// runRunbookStep executes the current runbook step
+func (m *Model) runRunbookStep(command string) tea.Cmd {
+	run := m.run
+	if run == nil {
+		return nil
+	}
+	run.Start()
+	m.isRunning = true
+	m.status = fmt.Sprintf("Runbook step %d/%d...", run.Current+1, len(run.Runbook.Steps))
+
+	done := func(result *executor.Result) tea.Msg {
+		return RunbookStepMsg{Result: result}
+	}
+	if commands.IsInteractive(command, nil) {
+		return m.runInteractive(command, m.execOptions(nil), done)
+	}
+	exec := m.executor
+	exec.Options = m.execOptions(nil)
+	m.releaseOutput()
+	m.outputPanel.StartLive(m.runbookStepHeader(run)+executor.FormatHeader(command, m.session.Dir, exec.Options.TargetName()), nil)
+	return streamCommand(0, func(onChunk func(executor.Chunk)) *executor.Result {
+		return exec.ExecuteStream(command, onChunk)
+	}, done)
+}
+
+// runbookStepHeader describes the current step above its output
+func (m *Model) runbookStepHeader(run *runbook.Run) string {
+	step := run.CurrentStep()
+	name := step.Name
+	if name == "" {
+		name = step.Command
+	}
+	return fmt.Sprintf("📒 Runbook: %s ─ step %d/%d: %s\n", run.Runbook.Name, run.Current+1, len(run.Runbook.Steps), name)
+}
+
+// handleRunbookStep records a finished runbook step and moves on to the next one
+func (m *Model) handleRunbookStep(result *executor.Result) tea.Cmd {
+	m.isRunning = false
+	m.recordHistory(result)
+	run := m.run
+	if run == nil {
+		// The run was closed while the step was executing
+		m.outputPanel.FinishLive("", result)
+		return nil
+	}
+
+	m.outputPanel.FinishLive(m.runbookStepHeader(run), result)
+
+	run.Complete(result)
+	m.status = ""
+	return m.advanceRunbook()
+}
+
+// closeRunbook stops showing the active runbook, cancelling a running step
+func (m *Model) closeRunbook() {
+	if m.isRunning {
+		m.executor.Cancel()
+	}
+	m.run = nil
+	m.runbookPanel.SetRun(nil)
+	m.status = "Runbook closed"
+}
+
+// handleRunbookKey handles keys that control the active runbook.
+// handled is false for keys that should fall through to the normal handling.
+func (m *Model) handleRunbookKey(msg tea.KeyMsg) (model tea.Model, cmd tea.Cmd, handled bool) {
+	run := m.run
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.closeRunbook()
+		return m, nil, true
+
+	case tea.KeyCtrlC:
+		if m.isRunning {
+			m.executor.Cancel()
+			m.status = "Stopping step..."
+			return m, nil, true
+		}
+		m.closeRunbook()
+		return m, nil, true
+
+	case tea.KeyEnter:
+		if run.Paused {
+			run.TogglePause()
+		}
+		return m, m.advanceRunbook(), true
+
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "p":
+			run.TogglePause()
+			if run.Paused {
+				m.status = "Runbook paused: p to resume"
+				return m, nil, true
+			}
+			return m, m.advanceRunbook(), true
+		case "s":
+			if m.isRunning {
+				return m, nil, true
+			}
+			run.Skip()
+			return m, m.advanceRunbook(), true
+		case "r":
+			if m.isRunning {
+				return m, nil, true
+			}
+			run.Retry()
+			return m, m.advanceRunbook(), true
+		}
+		return m, nil, true
+
+	case tea.KeyTab, tea.KeyShiftTab, tea.KeySpace, tea.KeyBackspace, tea.KeyDelete,
+		tea.KeyLeft, tea.KeyRight, tea.KeyHome, tea.KeyEnd, tea.KeyCtrlU:
+		// The input is not used while a runbook is shown
+		return m, nil, true
+	}
+
+	return m, nil, false
+}
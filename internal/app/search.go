@@ -0,0 +1,87 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/ui"
+)
+
+// historySearchLimit is the number of matches kept for the history search
+const historySearchLimit = 50
+
+// openHistorySearch shows the history search, starting from the current input
+func (m *Model) openHistorySearch() {
+	m.historySearch = ui.NewHistorySearch(m.styles, m.inputPanel.Value)
+	m.historySearch.SetWidth(m.layout.ModalWidth())
+	m.updateHistorySearch()
+}
+
+// updateHistorySearch re-runs the search for the current query
+func (m *Model) updateHistorySearch() {
+	now := time.Now()
+	m.historySearch.Now = now
+	m.historySearch.SetItems(m.history.SearchRanked(m.historySearch.Query, now, historySearchLimit))
+}
+
+// handleHistorySearchKey handles keyboard input while the history search is open
+func (m *Model) handleHistorySearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	search := m.historySearch
+
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC, tea.KeyCtrlG:
+		m.historySearch = nil
+		return m, nil
+
+	case tea.KeyCtrlR:
+		// Repeated Ctrl+R cycles to the next match
+		search.Next()
+		return m, nil
+
+	case tea.KeyUp:
+		search.MoveUp()
+		return m, nil
+
+	case tea.KeyDown:
+		search.MoveDown()
+		return m, nil
+
+	case tea.KeyEnter, tea.KeyTab:
+		// Put the chosen command into the input for editing
+		if selected := search.GetSelected(); selected != nil {
+			m.inputPanel.SetValue(selected.Entry.Command)
+			m.activeTemplate = m.registry.FindByTemplate(selected.Entry.Command)
+			m.history.Reset()
+			m.updateSuggestions()
+		}
+		m.historySearch = nil
+		return m, nil
+
+	case tea.KeyBackspace:
+		search.DeleteChar()
+		m.updateHistorySearch()
+		return m, nil
+
+	case tea.KeyCtrlU:
+		search.Query = ""
+		m.updateHistorySearch()
+		return m, nil
+
+	case tea.KeySpace:
+		search.InsertChar(' ')
+		m.updateHistorySearch()
+		return m, nil
+
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r < 32 || r == 127 {
+				continue
+			}
+			search.InsertChar(r)
+		}
+		m.updateHistorySearch()
+		return m, nil
+	}
+
+	return m, nil
+}
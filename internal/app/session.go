@@ -0,0 +1,164 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/session"
+	"github.com/duladissa/architerm/internal/ui"
+)
+
+// execOptions returns how to run a command built from template (if any):
+// in the session's working directory and environment, with the configured
+// timeouts
+func (m *Model) execOptions(template *commands.Command) executor.Options {
+	opts := m.registry.GetExecutionConfig().Options(template)
+	opts.Dir = m.session.Dir
+	opts.Env = m.session.Environ()
+	if m.execTarget != nil {
+		opts.Target = m.execTarget
+		opts.SessionEnv = m.session.Vars()
+	}
+	return opts
+}
+
+// runBuiltin runs a built-in such as cd or export in the session, recording
+// it in history like other commands
+func (m *Model) runBuiltin(command, name string, args []string) {
+	m.history.Reset()
+	m.inputPanel.Clear()
+	m.activeTemplate = nil
+
+	result := &executor.Result{Command: command, StartTime: time.Now(), Dir: m.session.Dir}
+	var output string
+	var err error
+	if m.target != nil && isDirBuiltin(name) {
+		// Remote commands run in the target's own dir, not the session's
+		result.Target = m.target.Name
+		err = fmt.Errorf("%s: not supported on target %s; use \"cd DIR && COMMAND\" or set the target's dir", name, m.target.Name)
+	} else {
+		output, err = m.session.Run(name, args)
+	}
+	m.status = output
+	if err != nil {
+		result.ExitCode = 1
+		m.status = err.Error()
+	}
+	m.recordHistory(result)
+	m.sessionChanged()
+}
+
+// isDirBuiltin reports whether a built-in changes the working directory
+func isDirBuiltin(name string) bool {
+	return name == "cd" || name == "pushd" || name == "popd"
+}
+
+// sessionChanged passes the session's working directory and environment on
+// to the prompt, placeholder providers and suggestions
+func (m *Model) sessionChanged() {
+	m.inputPanel.Dir = m.session.Dir
+	m.resolver.SetOptions(m.execOptions(nil))
+	m.updateFrecencyScope()
+	m.updateSuggestions()
+}
+
+// openProfilePicker lists the configured env profiles and the .env files of
+// the working directory, with an entry to turn profiles off
+func (m *Model) openProfilePicker() {
+	m.profileChoices = []commands.EnvProfile{{Name: "none", Description: "Run commands with archiTerm's environment"}}
+	defined := make(map[string]bool)
+	for _, profile := range m.registry.GetEnvProfiles() {
+		m.profileChoices = append(m.profileChoices, profile)
+		defined[strings.ToLower(profile.Name)] = true
+	}
+	for _, path := range session.FindEnvFiles(m.session.Dir) {
+		name := session.EnvFileProfileName(path)
+		if !defined[strings.ToLower(name)] {
+			m.profileChoices = append(m.profileChoices, commands.EnvProfile{
+				Name:        name,
+				Description: "From " + session.Abbreviate(path),
+				EnvFiles:    []string{path},
+			})
+		}
+	}
+
+	active := ""
+	if m.session.Profile != nil {
+		active = m.session.Profile.Name
+	}
+	items := make([]ui.Choice, len(m.profileChoices))
+	for i, profile := range m.profileChoices {
+		description := profile.Description
+		if profile.Protected {
+			description = strings.TrimSpace("⚠ protected " + description)
+		}
+		items[i] = ui.Choice{
+			Name:        profile.Name,
+			Description: description,
+			Current:     (i == 0 && active == "") || (i > 0 && profile.Name == active),
+		}
+	}
+	m.profilePicker = ui.NewChoicePicker(m.styles, "🌐 Env profiles", items)
+	m.profilePicker.SetWidth(m.layout.ModalWidth())
+	m.status = "Choose an env profile"
+}
+
+// handleProfilePickerKey handles keyboard input while the profile picker is open
+func (m *Model) handleProfilePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC, tea.KeyCtrlE:
+		m.profilePicker = nil
+		m.status = "Cancelled"
+
+	case tea.KeyUp:
+		m.profilePicker.MoveUp()
+
+	case tea.KeyDown:
+		m.profilePicker.MoveDown()
+
+	case tea.KeyEnter:
+		i := m.profilePicker.GetSelected()
+		if i < 0 {
+			return m, nil
+		}
+		m.profilePicker = nil
+		m.switchProfile(i)
+	}
+	return m, nil
+}
+
+// switchProfile makes the i-th profile choice the active one; the first
+// choice turns profiles off
+func (m *Model) switchProfile(i int) {
+	if i == 0 {
+		m.session.SetProfile(nil)
+		m.status = "Env profile off"
+		m.sessionChanged()
+		return
+	}
+	profile, err := m.profileChoices[i].Load()
+	if err != nil {
+		m.status = fmt.Sprintf("Env profile error: %v", err)
+		return
+	}
+	m.session.SetProfile(profile)
+	m.status = fmt.Sprintf("Env profile: %s (%d variables)", profile.Name, len(profile.Vars))
+	m.sessionChanged()
+}
+
+// profileStatus shows the active env profile at the start of the status bar,
+// highlighting protected ones
+func (m *Model) profileStatus() string {
+	profile := m.session.Profile
+	if profile == nil {
+		return ""
+	}
+	if profile.Protected {
+		return m.styles.StatusProtected.Render(" ⚠ "+strings.ToUpper(profile.Name)+" ") + " "
+	}
+	return m.styles.StatusProfile.Render("🌐 "+profile.Name) + " "
+}
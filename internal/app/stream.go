@@ -0,0 +1,50 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/executor"
+)
+
+// outputBuffer is how many chunks a command can write ahead of the UI
+const outputBuffer = 64
+
+// OutputChunkMsg carries output of a running command to the output panel
+type OutputChunkMsg struct {
+	Chunk  executor.Chunk
+	Job    int // 0 for commands that are not jobs, such as runbook steps
+	stream <-chan tea.Msg
+}
+
+// streamCommand runs execute in the background, sending the output it passes
+// on as OutputChunkMsg and finally the message built by done
+func streamCommand(job int, execute func(onChunk func(executor.Chunk)) *executor.Result, done func(*executor.Result) tea.Msg) tea.Cmd {
+	stream := make(chan tea.Msg, outputBuffer)
+	go func() {
+		result := execute(func(chunk executor.Chunk) {
+			stream <- OutputChunkMsg{Chunk: chunk, Job: job, stream: stream}
+		})
+		stream <- done(result)
+		close(stream)
+	}()
+	return waitForOutput(stream)
+}
+
+// waitForOutput returns the next message from a running command
+func waitForOutput(stream <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-stream
+	}
+}
+
+// handleOutputChunk appends streamed output and waits for more
+func (m *Model) handleOutputChunk(msg OutputChunkMsg) tea.Cmd {
+	if msg.Job != 0 {
+		if job := m.jobs.Get(msg.Job); job != nil {
+			job.Append(msg.Chunk)
+		}
+	}
+	if msg.Job == m.outputJob {
+		m.outputPanel.AppendLive(msg.Chunk)
+	}
+	return waitForOutput(msg.stream)
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/ui"
+)
+
+// openTargetPicker lists this machine and the configured targets
+func (m *Model) openTargetPicker() {
+	items := []ui.Choice{{
+		Name:        executor.TargetLocal,
+		Description: "Run commands on this machine",
+		Current:     m.target == nil,
+	}}
+	for _, target := range m.registry.GetTargets() {
+		items = append(items, ui.Choice{
+			Name:        target.Name,
+			Description: targetDescription(target),
+			Current:     m.target != nil && strings.EqualFold(m.target.Name, target.Name),
+		})
+	}
+	m.targetPicker = ui.NewChoicePicker(m.styles, "🖥  Targets", items)
+	m.targetPicker.Empty = "No targets defined."
+	m.targetPicker.SetWidth(m.layout.ModalWidth())
+	m.status = "Choose where commands run"
+}
+
+// targetDescription describes a target for the target picker
+func targetDescription(target commands.TargetConfig) string {
+	if target.Description != "" {
+		return target.Description
+	}
+	switch target.Type {
+	case executor.TargetDocker:
+		return "docker exec " + target.Container
+	case executor.TargetSSH:
+		return "ssh " + target.Host
+	}
+	return target.Type
+}
+
+// handleTargetPickerKey handles keyboard input while the target picker is open
+func (m *Model) handleTargetPickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC, tea.KeyCtrlX:
+		m.targetPicker = nil
+		m.status = "Cancelled"
+
+	case tea.KeyUp:
+		m.targetPicker.MoveUp()
+
+	case tea.KeyDown:
+		m.targetPicker.MoveDown()
+
+	case tea.KeyEnter:
+		i := m.targetPicker.GetSelected()
+		if i < 0 {
+			return m, nil
+		}
+		m.targetPicker = nil
+		if i == 0 {
+			m.setTarget(nil)
+			m.status = "Target: local"
+			return m, nil
+		}
+		target := m.registry.GetTargets()[i-1]
+		m.setTarget(&target)
+		m.status = fmt.Sprintf("Target: %s (%s)", target.Name, targetDescription(target))
+	}
+	return m, nil
+}
+
+// setTarget makes commands run on target, or locally if it is nil
+func (m *Model) setTarget(target *commands.TargetConfig) {
+	m.target = target
+	m.execTarget = nil
+	if target != nil {
+		m.execTarget = target.Target()
+	}
+	m.sessionChanged()
+}
+
+// refreshTarget picks up changes to the active target after the config is
+// reloaded, falling back to running commands locally if it was removed
+func (m *Model) refreshTarget() {
+	if m.target == nil {
+		return
+	}
+	for _, target := range m.registry.GetTargets() {
+		if strings.EqualFold(target.Name, m.target.Name) && target.Target() != nil {
+			m.setTarget(&target)
+			return
+		}
+	}
+	m.setTarget(nil)
+}
+
+// targetStatus shows the active target in the status bar in place of the
+// working directory, which is local
+func (m *Model) targetStatus() string {
+	return m.styles.StatusProfile.Render("🖥  "+m.target.Name) + " (" + m.target.Type + ")"
+}
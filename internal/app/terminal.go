@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/vterm"
+)
+
+// TerminalUpdateMsg is sent when the screen of the terminal pane changes
+type TerminalUpdateMsg struct {
+	session *vterm.Session
+}
+
+// TerminalExitMsg is sent when the command in the terminal pane exits
+type TerminalExitMsg struct {
+	session *vterm.Session
+}
+
+// terminalKeys maps special keys to the sequences a terminal sends for them
+var terminalKeys = map[tea.KeyType]string{
+	tea.KeySpace:    " ",
+	tea.KeyHome:     "\x1b[H",
+	tea.KeyEnd:      "\x1b[F",
+	tea.KeyPgUp:     "\x1b[5~",
+	tea.KeyPgDown:   "\x1b[6~",
+	tea.KeyInsert:   "\x1b[2~",
+	tea.KeyDelete:   "\x1b[3~",
+	tea.KeyShiftTab: "\x1b[Z",
+	tea.KeyF1:       "\x1bOP",
+	tea.KeyF2:       "\x1bOQ",
+	tea.KeyF3:       "\x1bOR",
+	tea.KeyF4:       "\x1bOS",
+	tea.KeyF5:       "\x1b[15~",
+	tea.KeyF6:       "\x1b[17~",
+	tea.KeyF7:       "\x1b[18~",
+	tea.KeyF8:       "\x1b[19~",
+	tea.KeyF9:       "\x1b[20~",
+	tea.KeyF10:      "\x1b[21~",
+	tea.KeyF11:      "\x1b[23~",
+	tea.KeyF12:      "\x1b[24~",
+}
+
+// arrowKeys maps the arrow keys to their final byte
+var arrowKeys = map[tea.KeyType]byte{
+	tea.KeyUp:    'A',
+	tea.KeyDown:  'B',
+	tea.KeyRight: 'C',
+	tea.KeyLeft:  'D',
+}
+
+// runTerminal runs command with opts in the terminal pane, focused so keys
+// go to it
+func (m *Model) runTerminal(command string, opts executor.Options) tea.Cmd {
+	cols, rows := m.outputPanel.TerminalSize()
+	session, err := vterm.Start(command, opts, cols, rows)
+	if err != nil {
+		m.isRunning = false
+		m.status = fmt.Sprintf("Terminal error: %v", err)
+		return nil
+	}
+	m.terminal = session
+	m.releaseOutput()
+	m.outputPanel.ShowTerminal(command, session)
+	m.status = "Running in the terminal pane..."
+	return waitForTerminal(session)
+}
+
+// waitForTerminal waits for the next screen change or the exit of a terminal session
+func waitForTerminal(session *vterm.Session) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-session.Updates(); ok {
+			return TerminalUpdateMsg{session: session}
+		}
+		return TerminalExitMsg{session: session}
+	}
+}
+
+// handleTerminalExit replaces the terminal pane with the result of its command
+func (m *Model) handleTerminalExit(session *vterm.Session) {
+	if session == m.terminal {
+		m.terminal = nil
+		m.outputPanel.HideTerminal()
+	}
+	result := session.Result()
+	m.isRunning = false
+	m.status = ""
+	m.recordHistory(result)
+	m.outputPanel.FinishLive("", result)
+	// The final screen fills the panel; keep the exit status in view
+	m.outputPanel.ScrollToBottom()
+}
+
+// focusTerminal sends key presses to the terminal pane
+func (m *Model) focusTerminal() {
+	m.outputPanel.TerminalFocused = true
+	m.status = "Keys go to the command: Ctrl+] to release"
+}
+
+// handleTerminalKey forwards a key press to the focused terminal pane.
+// Ctrl+] gives the keyboard back to archiTerm.
+func (m *Model) handleTerminalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlCloseBracket {
+		m.outputPanel.TerminalFocused = false
+		m.status = "Terminal pane released: Alt+Enter to focus, Ctrl+C to kill"
+		return m, nil
+	}
+	if b := terminalKeyBytes(msg, m.terminal.AppCursor()); len(b) > 0 {
+		m.terminal.Write(b)
+	}
+	return m, nil
+}
+
+// terminalKeyBytes returns what a terminal sends for a key press
+func terminalKeyBytes(msg tea.KeyMsg, appCursor bool) []byte {
+	var b []byte
+	if final, ok := arrowKeys[msg.Type]; ok {
+		if appCursor {
+			b = []byte{0x1b, 'O', final}
+		} else {
+			b = []byte{0x1b, '[', final}
+		}
+	} else if seq, ok := terminalKeys[msg.Type]; ok {
+		b = []byte(seq)
+	} else if msg.Type == tea.KeyRunes {
+		b = []byte(string(msg.Runes))
+	} else if (msg.Type >= 0 && msg.Type < 32) || msg.Type == 127 {
+		// Control keys, Enter, Tab, Esc and Backspace are their own byte
+		b = []byte{byte(msg.Type)}
+	}
+	if msg.Alt && len(b) > 0 {
+		b = append([]byte{0x1b}, b...)
+	}
+	return b
+}
@@ -1,21 +1,31 @@
 package autocomplete
 
 import (
+	"math"
 	"sort"
 	"strings"
 )
 
+// maxBoost caps the score a frequently used command gains, so a strong text
+// match still beats a weak match of a popular command
+const maxBoost = 60
+
 // Match represents a matching command with its score
 type Match struct {
 	Command     string
 	Description string
 	Score       int
+	Favorite    bool
 }
 
 // Engine provides autocomplete functionality
 type Engine struct {
 	trie     *Trie
 	commands []Match
+	usage    func(command string) float64
+
+	// favorites maps favorite commands to their position in the favorites list
+	favorites map[string]int
 }
 
 // NewEngine creates a new autocomplete engine
@@ -42,14 +52,56 @@ func (e *Engine) AddCommands(commands []struct{ Command, Description string }) {
 	}
 }
 
+// SetUsage sets the function returning how often and how recently a command
+// was used (its frecency). Used commands rank higher in suggestions and ghost text.
+func (e *Engine) SetUsage(usage func(command string) float64) {
+	e.usage = usage
+}
+
+// SetFavorites sets the commands pinned to the top of the suggestions for empty input
+func (e *Engine) SetFavorites(commands []string) {
+	e.favorites = make(map[string]int, len(commands))
+	for i, command := range commands {
+		e.favorites[command] = i
+	}
+}
+
+// boost returns the score bonus for a command's usage
+func (e *Engine) boost(command string) int {
+	if e.usage == nil {
+		return 0
+	}
+	frecency := e.usage(command)
+	if frecency <= 0 {
+		return 0
+	}
+	return int(math.Min(maxBoost, 15*math.Log2(1+frecency)))
+}
+
 // GetSuggestions returns matching commands for the input
 func (e *Engine) GetSuggestions(input string, limit int) []Match {
 	if input == "" {
-		// Return first N commands
-		if limit > len(e.commands) {
-			limit = len(e.commands)
+		// Return favorites in their order, then the most used commands, then the rest
+		results := make([]Match, len(e.commands))
+		copy(results, e.commands)
+		for i := range results {
+			results[i].Score = e.boost(results[i].Command)
+			_, results[i].Favorite = e.favorites[results[i].Command]
 		}
-		return e.commands[:limit]
+		sort.SliceStable(results, func(i, j int) bool {
+			a, b := results[i], results[j]
+			if a.Favorite != b.Favorite {
+				return a.Favorite
+			}
+			if a.Favorite {
+				return e.favorites[a.Command] < e.favorites[b.Command]
+			}
+			return a.Score > b.Score
+		})
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+		return results
 	}
 
 	// First try prefix matching via trie
@@ -78,6 +130,11 @@ func (e *Engine) GetSuggestions(input string, limit int) []Match {
 		}
 	}
 
+	for i := range results {
+		results[i].Score += e.boost(results[i].Command)
+		_, results[i].Favorite = e.favorites[results[i].Command]
+	}
+
 	// Sort by score (descending)
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
@@ -96,6 +153,17 @@ func (e *Engine) GetGhostText(input string) string {
 		return ""
 	}
 
+	// Prefer the most used command with this prefix
+	best, bestBoost := "", 0
+	for _, m := range e.trie.Search(input) {
+		if b := e.boost(m.Command); b > bestBoost || (b == bestBoost && b > 0 && m.Command < best) {
+			best, bestBoost = m.Command, b
+		}
+	}
+	if best != "" {
+		return best[len(input):]
+	}
+
 	// Get the best prefix match
 	completion := e.trie.GetCompletion(input)
 	return completion
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Danger levels
+const (
+	DangerNone     = "none"     // Runs without confirmation
+	DangerCaution  = "caution"  // Asks for a yes/no confirmation
+	DangerCritical = "critical" // Requires typing the resource name
+)
+
+// DangerLevels lists the supported danger levels
+var DangerLevels = []string{DangerNone, DangerCaution, DangerCritical}
+
+// IsValidDangerLevel reports whether level is a supported danger level (empty means unset)
+func IsValidDangerLevel(level string) bool {
+	return level == "" || dangerRank(level) >= 0
+}
+
+// dangerRank orders danger levels, returning -1 for unknown levels
+func dangerRank(level string) int {
+	for i, known := range DangerLevels {
+		if level == known {
+			return i
+		}
+	}
+	return -1
+}
+
+// DangerRule flags typed commands matching a regex as destructive.
+// A named group "resource" in the pattern selects the name to type for
+// critical commands; without it the matched text has to be typed.
+// Rules are identified by ID so config can override them.
+type DangerRule struct {
+	ID      string `yaml:"id" json:"id"`
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Level   string `yaml:"level,omitempty" json:"level,omitempty"`
+	Reason  string `yaml:"reason,omitempty" json:"reason,omitempty"`
+
+	// Source is the config layer that last defined or changed the rule
+	Source string `yaml:"-" json:"-"`
+}
+
+// DefaultDangerRules returns the built-in destructive command rules
+func DefaultDangerRules() []DangerRule {
+	rules := []DangerRule{
+		{ID: "terraform-destroy", Pattern: `\bterraform\b.*\s(?:destroy\b|apply\b.*\s-destroy\b)`, Level: DangerCritical, Reason: "destroys managed infrastructure"},
+		{ID: "kubectl-delete", Pattern: `\bkubectl\b.*\sdelete\b`, Level: DangerCaution, Reason: "deletes Kubernetes resources"},
+		{ID: "kubectl-delete-named", Pattern: `\bkubectl\b.*\sdelete\s+(?:-\S+\s+)*\S+?[\s/]+(?P<resource>[^-\s]\S*)`, Level: DangerCritical, Reason: "deletes Kubernetes resources"},
+		{ID: "kubectl-drain", Pattern: `\bkubectl\b.*\s(?:drain|cordon)\b`, Level: DangerCaution, Reason: "evicts workloads from a node"},
+		{ID: "helm-uninstall", Pattern: `\bhelm\s+(?:uninstall|delete)\s+(?P<resource>[^-\s]\S*)`, Level: DangerCritical, Reason: "removes a Helm release"},
+		{ID: "docker-prune", Pattern: `\bdocker\s+(?:system|volume|image|container|network)\s+prune\b`, Level: DangerCaution, Reason: "removes unused Docker data"},
+		{ID: "docker-prune-all", Pattern: `\bdocker\s+(?:system|volume)\s+prune\b.*\s(?:-a|--all|--volumes)\b`, Level: DangerCritical, Reason: "removes all unused Docker data including volumes"},
+		{ID: "docker-remove", Pattern: `\bdocker\s+(?:rm|rmi|container\s+rm|image\s+rm|volume\s+rm)\b`, Level: DangerCaution, Reason: "removes Docker objects"},
+		{ID: "git-force-push", Pattern: `\bgit\s+push\b.*\s(?:--force(?:-with-lease)?|-f)\b`, Level: DangerCritical, Reason: "rewrites remote history"},
+		{ID: "git-discard", Pattern: `\bgit\s+(?:reset\s+--hard|clean\s+-\S*f)`, Level: DangerCaution, Reason: "discards local changes"},
+		{ID: "rm-recursive", Pattern: `\brm\s+(?:-\S+\s+)*-\S*[rR]`, Level: DangerCaution, Reason: "recursively deletes files"},
+		{ID: "sql-drop", Pattern: `(?i)\bdrop\s+(?:table|database|schema)\s+(?:if\s+exists\s+)?(?P<resource>[\w.]+)`, Level: DangerCritical, Reason: "drops database objects"},
+		{ID: "disk-format", Pattern: `\b(?:mkfs(?:\.\w+)?|wipefs)\b|\bdd\b.*\bof=/dev/`, Level: DangerCritical, Reason: "overwrites a disk"},
+		{ID: "cloud-delete", Pattern: `\b(?:gcloud|az|aws)\b.*\s(?:delete|terminate-instances|delete-bucket)\b`, Level: DangerCaution, Reason: "deletes cloud resources"},
+		{ID: "firewall-flush", Pattern: `\biptables\s+(?:-F|--flush)\b`, Level: DangerCaution, Reason: "flushes firewall rules"},
+	}
+	for i := range rules {
+		rules[i].Source = "built-in"
+	}
+	return rules
+}
+
+// MergeDangerRules overlays override rules onto base by ID. Non-empty fields of
+// an override replace the base rule's; rules with a new or empty ID are appended.
+// Unless canLower is set, overrides may only raise the level of an existing
+// rule: lower levels and new patterns, which could stop it matching, are ignored.
+func MergeDangerRules(base, overrides []DangerRule, canLower bool) []DangerRule {
+	merged := make([]DangerRule, len(base))
+	copy(merged, base)
+
+	for _, override := range overrides {
+		found := false
+		if override.ID != "" {
+			for i := range merged {
+				if merged[i].ID != override.ID {
+					continue
+				}
+				if !canLower {
+					override.Pattern = ""
+					if dangerRank(override.Level) < dangerRank(merged[i].Level) {
+						override.Level = ""
+					}
+				}
+				if override.Pattern != "" {
+					merged[i].Pattern = override.Pattern
+				}
+				if override.Level != "" {
+					merged[i].Level = override.Level
+				}
+				if override.Reason != "" {
+					merged[i].Reason = override.Reason
+				}
+				merged[i].Source = override.Source
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, override)
+		}
+	}
+	return merged
+}
+
+// Danger is the outcome of checking a command against the danger rules
+type Danger struct {
+	Level   string
+	Reason  string
+	Confirm string // Text to type before a critical command runs
+}
+
+// IsDangerous returns true if the command needs confirmation
+func (d Danger) IsDangerous() bool {
+	return d.Level == DangerCaution || d.Level == DangerCritical
+}
+
+// AssessDanger determines how dangerous a command is. The highest matching
+// rule wins; a level declared on the command template raises it, but only
+// while the command is still that template with its placeholders filled in.
+// Rules with invalid patterns are ignored.
+func AssessDanger(command string, template *Command, rules []DangerRule) Danger {
+	var danger Danger
+	best := -1
+
+	for _, rule := range rules {
+		rank := dangerRank(rule.Level)
+		if rule.Pattern == "" || rank <= best {
+			continue
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			continue
+		}
+		match := re.FindStringSubmatch(command)
+		if match == nil {
+			continue
+		}
+		best = rank
+		danger = Danger{Level: rule.Level, Reason: rule.Reason, Confirm: strings.TrimSpace(match[0])}
+		if idx := re.SubexpIndex("resource"); idx > 0 && idx < len(match) {
+			danger.Confirm = match[idx]
+		}
+	}
+
+	if template != nil && template.Danger != "" && IsValidDangerLevel(template.Danger) {
+		if _, ok := MatchTemplate(template.Template, template.GetPlaceholders(), command); ok {
+			if dangerRank(template.Danger) > best {
+				danger = Danger{Level: template.Danger, Reason: danger.Reason}
+			}
+			if danger.Reason == "" {
+				danger.Reason = template.Description
+			}
+		}
+	}
+
+	if danger.Level == DangerCritical && danger.Confirm == "" {
+		danger.Confirm = lastArgument(command)
+	}
+	return danger
+}
+
+// lastArgument returns the last word of a command that is not a flag
+func lastArgument(command string) string {
+	fields := strings.Fields(command)
+	for i := len(fields) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(fields[i], "-") {
+			return strings.Trim(fields[i], `'"`)
+		}
+	}
+	return command
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAssessDanger(t *testing.T) {
+	rules := DefaultDangerRules()
+	tests := []struct {
+		name     string
+		command  string
+		template *Command
+		want     Danger
+	}{
+		{"harmless", "kubectl get pods", nil, Danger{}},
+		{"caution", "git reset --hard HEAD~1", nil, Danger{Level: DangerCaution, Reason: "discards local changes", Confirm: "git reset --hard"}},
+		{"critical with resource", "kubectl delete deployment web -n prod", nil,
+			Danger{Level: DangerCritical, Reason: "deletes Kubernetes resources", Confirm: "web"}},
+		{"highest rule wins", "docker system prune -a", nil,
+			Danger{Level: DangerCritical, Reason: "removes all unused Docker data including volumes", Confirm: "docker system prune -a"}},
+		{"sql is case insensitive", `psql -c "DROP TABLE users"`, nil, Danger{Level: DangerCritical, Reason: "drops database objects", Confirm: "users"}},
+		{"template raises level", "make deploy ENV=prod", &Command{Template: "make deploy ENV=prod", Description: "Deploy", Danger: DangerCaution},
+			Danger{Level: DangerCaution, Reason: "Deploy"}},
+		{"critical template confirms last argument", "terraform workspace delete staging",
+			&Command{Template: "terraform workspace delete WORKSPACE", Danger: DangerCritical},
+			Danger{Level: DangerCritical, Confirm: "staging"}},
+		{"template cannot lower a rule", "rm -rf build", &Command{Template: "rm -rf DIR", Danger: DangerNone},
+			Danger{Level: DangerCaution, Reason: "recursively deletes files", Confirm: "rm -r"}},
+		{"template edited away", "make plan env=prod", &Command{Template: "make deploy env=ENVIRONMENT", Danger: DangerCritical},
+			Danger{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AssessDanger(tt.command, tt.template, rules); got != tt.want {
+				t.Errorf("AssessDanger(%q) = %+v, want %+v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssessDangerIgnoresInvalidRules(t *testing.T) {
+	rules := []DangerRule{
+		{ID: "broken", Pattern: `(`, Level: DangerCritical},
+		{ID: "no-level", Pattern: `reboot`, Level: "high"},
+		{ID: "reboot", Pattern: `\breboot\b`, Level: DangerCaution, Reason: "restarts the host"},
+	}
+	want := Danger{Level: DangerCaution, Reason: "restarts the host", Confirm: "reboot"}
+	if got := AssessDanger("sudo reboot", nil, rules); got != want {
+		t.Errorf("AssessDanger() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeDangerRules(t *testing.T) {
+	base := []DangerRule{
+		{ID: "rm-recursive", Pattern: `\brm\s+-r`, Level: DangerCaution, Reason: "recursively deletes files"},
+		{ID: "git-discard", Pattern: `\bgit\s+reset\s+--hard`, Level: DangerCaution, Reason: "discards local changes"},
+	}
+	tests := []struct {
+		name     string
+		override DangerRule
+		canLower bool
+		want     DangerRule
+	}{
+		{"raise", DangerRule{ID: "rm-recursive", Level: DangerCritical}, true,
+			DangerRule{ID: "rm-recursive", Pattern: `\brm\s+-r`, Level: DangerCritical, Reason: "recursively deletes files"}},
+		{"lower", DangerRule{ID: "rm-recursive", Level: DangerNone}, true,
+			DangerRule{ID: "rm-recursive", Pattern: `\brm\s+-r`, Level: DangerNone, Reason: "recursively deletes files"}},
+		{"pattern and reason", DangerRule{ID: "git-discard", Pattern: `\bgit\s+checkout\s+--\s`, Reason: "discards changes"}, true,
+			DangerRule{ID: "git-discard", Pattern: `\bgit\s+checkout\s+--\s`, Level: DangerCaution, Reason: "discards changes"}},
+		{"restricted raise", DangerRule{ID: "rm-recursive", Level: DangerCritical}, false,
+			DangerRule{ID: "rm-recursive", Pattern: `\brm\s+-r`, Level: DangerCritical, Reason: "recursively deletes files"}},
+		{"restricted same level", DangerRule{ID: "rm-recursive", Level: DangerCaution, Reason: "deletes trees"}, false,
+			DangerRule{ID: "rm-recursive", Pattern: `\brm\s+-r`, Level: DangerCaution, Reason: "deletes trees"}},
+		{"restricted lower", DangerRule{ID: "rm-recursive", Level: DangerNone}, false,
+			DangerRule{ID: "rm-recursive", Pattern: `\brm\s+-r`, Level: DangerCaution, Reason: "recursively deletes files"}},
+		{"restricted pattern", DangerRule{ID: "git-discard", Pattern: `^$`, Level: DangerCritical}, false,
+			DangerRule{ID: "git-discard", Pattern: `\bgit\s+reset\s+--hard`, Level: DangerCritical, Reason: "discards local changes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeDangerRules(base, []DangerRule{tt.override}, tt.canLower)
+			if len(got) != len(base) {
+				t.Fatalf("MergeDangerRules() returned %d rules, want %d", len(got), len(base))
+			}
+			if i := indexOfRule(got, tt.want.ID); i < 0 || !reflect.DeepEqual(got[i], tt.want) {
+				t.Errorf("MergeDangerRules() =\n%+v\nwant %+v", got, tt.want)
+			}
+			if base[0].Level != DangerCaution || base[1].Pattern != `\bgit\s+reset\s+--hard` {
+				t.Error("MergeDangerRules() changed the base rules")
+			}
+		})
+	}
+}
+
+func TestMergeDangerRulesAppends(t *testing.T) {
+	base := []DangerRule{{ID: "rm-recursive", Pattern: `\brm\s+-r`, Level: DangerCaution}}
+	for _, canLower := range []bool{true, false} {
+		added := DangerRule{ID: "reboot", Pattern: `\breboot\b`, Level: DangerCaution}
+		got := MergeDangerRules(base, []DangerRule{added}, canLower)
+		if want := append(base[:1:1], added); !reflect.DeepEqual(got, want) {
+			t.Errorf("MergeDangerRules(canLower=%v) = %+v, want %+v", canLower, got, want)
+		}
+	}
+}
+
+func TestLayerCanLowerDanger(t *testing.T) {
+	tests := []struct {
+		layer string
+		want  bool
+	}{
+		{LayerSystem, true},
+		{LayerSystemConfD, false},
+		{LayerUser, true},
+		{LayerUserConfD, false},
+		{LayerProject, false},
+		{LayerExplicit, true},
+	}
+	for _, tt := range tests {
+		if got := (Layer{Name: tt.layer}).CanLowerDanger(); got != tt.want {
+			t.Errorf("Layer{%s}.CanLowerDanger() = %v, want %v", tt.layer, got, tt.want)
+		}
+	}
+}
+
+func TestLoadLayersDangerRules(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "commands.yaml")
+	project := filepath.Join(dir, ProjectConfigName)
+	writeFile(t, user, "danger_rules:\n  - id: git-force-push\n    level: caution\n")
+	writeFile(t, project, "danger_rules:\n  - id: rm-recursive\n    level: none\n  - id: git-force-push\n    level: critical\n")
+
+	r := NewRegistry()
+	errs := r.LoadLayers([]Layer{
+		{Name: LayerUser, Path: user, Exists: true},
+		{Name: LayerProject, Path: project, Exists: true},
+	})
+	if len(errs) > 0 {
+		t.Fatalf("LoadLayers() errors = %v", errs)
+	}
+	if got := r.AssessDanger("rm -r build", nil).Level; got != DangerCaution {
+		t.Errorf("project layer lowered rm-recursive to %q", got)
+	}
+	if got := r.AssessDanger("git push --force origin main", nil).Level; got != DangerCritical {
+		t.Errorf("project layer did not raise git-force-push: %q", got)
+	}
+}
+
+// indexOfRule returns the index of the rule with the given ID, or -1
+func indexOfRule(rules []DangerRule, id string) int {
+	for i, rule := range rules {
+		if rule.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeFile writes content to path, failing the test on error
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/duladissa/architerm/internal/filelock"
+	"gopkg.in/yaml.v3"
+)
+
+// FavoritesFileName is the user conf.d drop-in that archiTerm writes starred commands to
+const FavoritesFileName = "favorites.yaml"
+
+// DefaultFavoriteSet is the name of the set holding the "favorites" list
+const DefaultFavoriteSet = "Favorites"
+
+// favoritesHeader starts the favorites file written by ToggleFavorite
+const favoritesHeader = "# Favorites starred in archiTerm with Ctrl+F.\n# This file is updated when favorites change; group commands under favorite_sets.\n"
+
+// FavoriteSet is a named group of pinned commands
+type FavoriteSet struct {
+	Name     string   `yaml:"name" json:"name"`
+	Commands []string `yaml:"commands" json:"commands"`
+
+	// Source is the config layer the set came from
+	Source string `yaml:"-" json:"-"`
+}
+
+// GetFavoritesPath returns the path of the favorites drop-in in the user config directory
+func GetFavoritesPath() string {
+	return filepath.Join(GetUserConfigDir(), "conf.d", FavoritesFileName)
+}
+
+// addFavorites adds templates to the favorites list, skipping ones already in it
+func (r *Registry) addFavorites(templates []string, source string) {
+	for _, template := range templates {
+		if template == "" || r.IsFavorite(template) {
+			continue
+		}
+		r.favorites = append(r.favorites, template)
+		r.favoriteSources = append(r.favoriteSources, source)
+	}
+}
+
+// upsertFavoriteSet replaces the set with the same name, or appends it
+func (r *Registry) upsertFavoriteSet(set FavoriteSet) {
+	for i := range r.favoriteSets {
+		if strings.EqualFold(r.favoriteSets[i].Name, set.Name) {
+			r.favoriteSets[i] = set
+			return
+		}
+	}
+	r.favoriteSets = append(r.favoriteSets, set)
+}
+
+// GetFavorites returns the favorite templates in the order they were added
+func (r *Registry) GetFavorites() []string {
+	return r.favorites
+}
+
+// IsFavorite reports whether a template is in the favorites list
+func (r *Registry) IsFavorite(template string) bool {
+	return r.FavoriteSource(template) != ""
+}
+
+// FavoriteSource returns the config layer a favorite came from, or "" if it is not one
+func (r *Registry) FavoriteSource(template string) string {
+	for i, favorite := range r.favorites {
+		if favorite == template {
+			return r.favoriteSources[i]
+		}
+	}
+	return ""
+}
+
+// GetFavoriteSets returns the favorites list as the first set, followed by
+// the named sets. The favorites list is included even if empty.
+func (r *Registry) GetFavoriteSets() []FavoriteSet {
+	sets := []FavoriteSet{{Name: DefaultFavoriteSet, Commands: r.favorites}}
+	return append(sets, r.favoriteSets...)
+}
+
+// ToggleFavorite adds template to the favorites list of the file at path, or
+// removes it if already there. It returns true if the template was added.
+// The file is edited under its lock and replaced atomically, keeping its
+// other content and comments.
+func ToggleFavorite(path, template string) (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	unlock, err := filelock.Lock(path)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	header := ""
+	if doc.Kind == 0 {
+		// New or empty file
+		header = favoritesHeader
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return false, fmt.Errorf("%s: expected a YAML mapping", path)
+	}
+	root := doc.Content[0]
+
+	list := mappingValue(root, "favorites")
+	if list == nil {
+		list = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "favorites"}, list)
+	}
+	if list.Kind != yaml.SequenceNode {
+		return false, fmt.Errorf("%s:%d: favorites must be a list", path, list.Line)
+	}
+
+	added := true
+	var items []*yaml.Node
+	for _, item := range list.Content {
+		if item.Value == template {
+			added = false
+			continue
+		}
+		items = append(items, item)
+	}
+	if added {
+		items = append(items, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: template})
+	}
+	list.Content = items
+	if len(items) > 0 {
+		// An empty list parses as flow style ([]); write entries one per line
+		list.Style = 0
+	}
+
+	body, err := MarshalYAML(&doc)
+	if err != nil {
+		return false, err
+	}
+	return added, filelock.WriteFile(path, append([]byte(header), body...))
+}
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// segmentSeparator splits a command line into the commands of a pipeline or list
+var segmentSeparator = regexp.MustCompile(`\|\||&&|[|;&]`)
+
+// fullScreenPrograms always take over the terminal
+var fullScreenPrograms = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "nano": true, "emacs": true, "micro": true,
+	"less": true, "more": true, "man": true,
+	"top": true, "htop": true, "btop": true, "atop": true, "watch": true,
+	"tig": true, "lazygit": true, "lazydocker": true, "k9s": true,
+	"mc": true, "ranger": true, "nnn": true, "visudo": true,
+}
+
+// replPrograms start a prompt when run without a script or inline code
+var replPrograms = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "fish": true,
+	"python": true, "python3": true, "ipython": true, "node": true, "irb": true,
+}
+
+// dbClients open a prompt unless given a query or file to run
+var dbClients = map[string]bool{
+	"psql": true, "mysql": true, "mariadb": true, "sqlite3": true,
+	"redis-cli": true, "mongosh": true, "mongo": true,
+}
+
+// containerTools take -it (or --interactive --tty) to attach the terminal, as
+// do kubectl and oc
+var containerTools = map[string]bool{
+	"docker": true, "podman": true, "nerdctl": true,
+}
+
+// sshValueFlags are ssh options that take a value
+const sshValueFlags = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// IsInteractive reports whether command needs a terminal, such as a shell in
+// a container, an ssh login, tmux or an editor. The template's interactive
+// field wins; otherwise each command of a pipeline or list is checked.
+func IsInteractive(command string, template *Command) bool {
+	if template != nil && template.Interactive != nil {
+		return *template.Interactive
+	}
+	for _, segment := range segmentSeparator.Split(command, -1) {
+		if isInteractiveSegment(strings.Fields(segment)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInteractiveSegment checks one simple command split into words
+func isInteractiveSegment(words []string) bool {
+	// Skip variable assignments and wrappers that run the rest of the line
+	for len(words) > 0 {
+		switch {
+		case words[0] == "sudo":
+			words = words[1:]
+			for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+				if words[0] == "-i" || words[0] == "-s" {
+					return true // Login or shell as another user
+				}
+				if words[0] == "-u" || words[0] == "-g" {
+					words = words[1:]
+				}
+				words = words[1:]
+			}
+			continue
+		case words[0] == "exec" || words[0] == "time" || strings.Contains(words[0], "="):
+			words = words[1:]
+			continue
+		}
+		break
+	}
+	if len(words) == 0 {
+		return false
+	}
+	program := filepath.Base(words[0])
+	args := words[1:]
+
+	switch {
+	case fullScreenPrograms[program]:
+		return true
+	case replPrograms[program]:
+		return len(positionalArgs(args)) == 0 && !hasFlag(args, "-c", "-e", "-m", "--eval")
+	case dbClients[program]:
+		return !hasFlag(args, "-c", "--command", "-e", "--execute", "-f", "--file", "--eval") && !strings.Contains(strings.Join(args, " "), "<")
+	case program == "kubectl" || program == "oc":
+		return hasTTYFlags(args) || (len(args) > 0 && args[0] == "edit")
+	case containerTools[program]:
+		return hasTTYFlags(args) || (len(args) > 0 && args[0] == "attach")
+	case program == "ssh":
+		return isInteractiveSSH(args)
+	case program == "tmux":
+		return isInteractiveTmux(args)
+	case program == "screen":
+		return !hasFlag(args, "-ls", "-list", "-dm", "-dmS")
+	case program == "su":
+		return true
+	case program == "crontab":
+		return hasFlag(args, "-e")
+	case program == "git":
+		return isInteractiveGit(args)
+	}
+	return false
+}
+
+// hasTTYFlags reports whether args attach stdin and a TTY (-it, or -i and -t)
+// without detaching
+func hasTTYFlags(args []string) bool {
+	var stdin, tty bool
+	for _, arg := range args {
+		switch {
+		case arg == "--":
+			return stdin && tty
+		case arg == "--interactive" || arg == "--stdin":
+			stdin = true
+		case arg == "--tty":
+			tty = true
+		case arg == "-d" || arg == "--detach":
+			return false
+		case strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--"):
+			stdin = stdin || strings.Contains(arg, "i")
+			tty = tty || strings.Contains(arg, "t")
+			if strings.Contains(arg, "d") {
+				return false
+			}
+		}
+	}
+	return stdin && tty
+}
+
+// isInteractiveSSH reports whether ssh opens a login shell: no remote command
+// is given, or a TTY is forced with -t
+func isInteractiveSSH(args []string) bool {
+	host := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if host {
+			// Anything after the host is a remote command
+			return false
+		}
+		if !strings.HasPrefix(arg, "-") {
+			host = true
+			continue
+		}
+		flags := arg[1:]
+		if strings.Contains(flags, "N") {
+			return false // Port forwarding only
+		}
+		if strings.Contains(flags, "t") {
+			return true
+		}
+		if len(flags) == 1 && strings.Contains(sshValueFlags, flags) {
+			i++ // Skip the option's value
+		}
+	}
+	return host
+}
+
+// isInteractiveTmux reports whether tmux attaches to a session
+func isInteractiveTmux(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	switch args[0] {
+	case "attach", "attach-session", "a", "at", "new", "new-session":
+		return !hasFlag(args[1:], "-d")
+	}
+	return false
+}
+
+// isInteractiveGit reports whether a git command prompts or opens an editor
+func isInteractiveGit(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "add", "checkout", "reset", "restore", "stash":
+		return hasFlag(args[1:], "-p", "--patch", "-i", "--interactive")
+	case "rebase":
+		return hasFlag(args[1:], "-i", "--interactive")
+	case "commit":
+		return !hasShortFlag(args[1:], "mFC") && !hasFlagPrefix(args[1:], "--message", "--file", "--no-edit", "--reuse-message")
+	}
+	return false
+}
+
+// positionalArgs returns the arguments that are not flags
+func positionalArgs(args []string) []string {
+	var positional []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			positional = append(positional, arg)
+		}
+	}
+	return positional
+}
+
+// hasFlag reports whether args contain one of flags
+func hasFlag(args []string, flags ...string) bool {
+	for _, arg := range args {
+		for _, flag := range flags {
+			if arg == flag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasShortFlag reports whether a short flag or flag group (like -am) in args
+// contains one of letters
+func hasShortFlag(args []string, letters string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.ContainsAny(arg[1:], letters) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFlagPrefix reports whether an argument starts with one of prefixes, such
+// as --message=text
+func hasFlagPrefix(args []string, prefixes ...string) bool {
+	for _, arg := range args {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(arg, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/duladissa/architerm/internal/session"
+)
+
+// ProjectConfigName is the project-local config file discovered by walking up from the working directory
+const ProjectConfigName = ".architerm.yaml"
+
+// Layer names in precedence order (later layers override earlier ones)
+const (
+	LayerEmbedded    = "embedded"
+	LayerSystem      = "system"
+	LayerSystemConfD = "system conf.d"
+	LayerUser        = "user"
+	LayerUserConfD   = "user conf.d"
+	LayerProject     = "project"
+	LayerExplicit    = "explicit"
+)
+
+// Layer is a single configuration file in the precedence chain
+type Layer struct {
+	Name   string
+	Path   string
+	Exists bool
+}
+
+// CanLowerDanger reports whether the layer may lower or re-pattern existing
+// danger rules. Project and conf.d files often come from a repository or a
+// package rather than the user, so they may only add rules or raise levels.
+func (l Layer) CanLowerDanger() bool {
+	switch l.Name {
+	case LayerSystemConfD, LayerUserConfD, LayerProject:
+		return false
+	}
+	return true
+}
+
+// GetSystemConfigDir returns the system-wide config directory
+func GetSystemConfigDir() string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			return filepath.Join(dir, "architerm")
+		}
+	}
+	return filepath.Join("/etc", "architerm")
+}
+
+// GetUserConfigDir returns the per-user config directory
+func GetUserConfigDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "architerm")
+}
+
+// DiscoverLayers returns the configuration files in precedence order:
+// system file, system conf.d, user file, user conf.d, project file and the
+// explicit --config file. The embedded packs always come first and are not listed.
+func DiscoverLayers(explicitPath string) []Layer {
+	var layers []Layer
+
+	systemDir := GetSystemConfigDir()
+	layers = append(layers, configFileLayer(LayerSystem, systemDir))
+	layers = append(layers, confDLayers(LayerSystemConfD, systemDir)...)
+
+	if userDir := GetUserConfigDir(); userDir != "" {
+		layers = append(layers, configFileLayer(LayerUser, userDir))
+		layers = append(layers, confDLayers(LayerUserConfD, userDir)...)
+	}
+
+	if wd, err := os.Getwd(); err == nil {
+		if path := FindProjectConfig(wd); path != "" {
+			layers = append(layers, Layer{Name: LayerProject, Path: path, Exists: true})
+		} else {
+			layers = append(layers, Layer{Name: LayerProject, Path: ProjectConfigName})
+		}
+	}
+
+	if explicitPath != "" {
+		layers = append(layers, Layer{Name: LayerExplicit, Path: explicitPath, Exists: fileExists(explicitPath)})
+	}
+
+	return layers
+}
+
+// FindProjectConfig walks up from dir looking for a project config file.
+// Returns an empty string if none is found.
+func FindProjectConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, name := range []string{ProjectConfigName, strings.TrimSuffix(ProjectConfigName, ".yaml") + ".yml"} {
+			path := filepath.Join(dir, name)
+			if fileExists(path) {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// IsTrustedProject reports whether the project config file at path is listed,
+// by its directory or its own path, under trusted_projects
+func (r *Registry) IsTrustedProject(path string) bool {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, trusted := range r.trustedProjects {
+		trusted, err := filepath.Abs(session.ExpandHome(trusted))
+		if err != nil {
+			continue
+		}
+		if trusted == path || trusted == filepath.Dir(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// restrictProjectConfig strips the settings of an untrusted project config
+// that run programs or change how commands run, keeping its commands,
+// runbooks, placeholders without providers, danger rules (which a project may
+// only raise) and cosmetic settings. It returns the names of what was removed.
+func restrictProjectConfig(config *Config) []string {
+	var ignored []string
+	if config.Execution != nil {
+		config.Execution = nil
+		ignored = append(ignored, "execution")
+	}
+	if config.History != nil {
+		config.History = nil
+		ignored = append(ignored, "history")
+	}
+	if len(config.EnvProfiles) > 0 {
+		config.EnvProfiles = nil
+		ignored = append(ignored, "env_profiles")
+	}
+	if len(config.Targets) > 0 {
+		config.Targets = nil
+		ignored = append(ignored, "targets")
+	}
+	if len(config.TrustedProjects) > 0 {
+		config.TrustedProjects = nil
+		ignored = append(ignored, "trusted_projects")
+	}
+
+	shells, providers := false, false
+	stripProviders := func(placeholders []Placeholder) {
+		for i := range placeholders {
+			if placeholders[i].Provider != nil {
+				placeholders[i].Provider = nil
+				providers = true
+			}
+		}
+	}
+	for i := range config.Commands {
+		if config.Commands[i].Shell != "" {
+			config.Commands[i].Shell = ""
+			shells = true
+		}
+		stripProviders(config.Commands[i].Placeholders)
+	}
+	for i := range config.Runbooks {
+		stripProviders(config.Runbooks[i].Placeholders)
+	}
+	stripProviders(config.Placeholders)
+	if shells {
+		ignored = append(ignored, "command shells")
+	}
+	if providers {
+		ignored = append(ignored, "placeholder providers")
+	}
+	return ignored
+}
+
+// configFileLayer returns the commands file of a config directory, preferring YAML over JSON
+func configFileLayer(name, dir string) Layer {
+	for _, file := range []string{"commands.yaml", "commands.yml", "commands.json"} {
+		path := filepath.Join(dir, file)
+		if fileExists(path) {
+			return Layer{Name: name, Path: path, Exists: true}
+		}
+	}
+	return Layer{Name: name, Path: filepath.Join(dir, "commands.yaml")}
+}
+
+// confDLayers returns the drop-in files of a config directory's conf.d in lexical order
+func confDLayers(name, dir string) []Layer {
+	entries, err := os.ReadDir(filepath.Join(dir, "conf.d"))
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		files = append(files, entry.Name())
+	}
+	sort.Strings(files)
+
+	layers := make([]Layer, len(files))
+	for i, file := range files {
+		layers[i] = Layer{Name: name, Path: filepath.Join(dir, "conf.d", file), Exists: true}
+	}
+	return layers
+}
+
+// fileExists returns true if path exists and is not a directory
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const projectConfig = `commands:
+  - template: make deploy ENVIRONMENT
+    shell: ./bin/sh
+    placeholders:
+      - name: ENVIRONMENT
+        provider:
+          command: ./scripts/envs
+execution:
+  shell: ./bin/sh
+history:
+  file: /tmp/stolen.jsonl
+env_profiles:
+  - name: prod
+    env:
+      PATH: ./bin
+targets:
+  - name: box
+    type: ssh
+    host: evil.example.com
+`
+
+// loadProject loads a user config trusting the given paths, relative to a
+// temporary directory, and the project config of its "repo" directory
+func loadProject(t *testing.T, trusted ...string) (*Registry, string, []error) {
+	t.Helper()
+	dir := t.TempDir()
+	project := filepath.Join(dir, "repo", ProjectConfigName)
+	if err := os.Mkdir(filepath.Dir(project), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, project, projectConfig)
+
+	user := filepath.Join(dir, "commands.yaml")
+	var sb strings.Builder
+	sb.WriteString("trusted_projects:\n")
+	for _, path := range trusted {
+		sb.WriteString("  - " + filepath.Join(dir, path) + "\n")
+	}
+	writeFile(t, user, sb.String())
+
+	r := NewRegistry()
+	errs := r.LoadLayers([]Layer{
+		{Name: LayerUser, Path: user, Exists: true},
+		{Name: LayerProject, Path: project, Exists: true},
+	})
+	return r, project, errs
+}
+
+func TestUntrustedProjectConfig(t *testing.T) {
+	r, project, errs := loadProject(t, "other-repo")
+	if r.IsTrustedProject(project) {
+		t.Error("IsTrustedProject() = true for a project that is not listed")
+	}
+	if len(errs) != 1 {
+		t.Fatalf("LoadLayers() errors = %v, want one", errs)
+	}
+	for _, key := range []string{"execution", "history", "env_profiles", "targets", "command shells", "placeholder providers"} {
+		if !strings.Contains(errs[0].Error(), key) {
+			t.Errorf("LoadLayers() error %q does not mention %s", errs[0], key)
+		}
+	}
+
+	cmd := r.FindByTemplate("make deploy ENVIRONMENT")
+	if cmd == nil {
+		t.Fatal("untrusted project command was not loaded")
+	}
+	if cmd.Shell != "" || cmd.Placeholders[0].Provider != nil {
+		t.Errorf("untrusted project command kept its shell or provider: %+v", cmd)
+	}
+	if r.GetExecutionConfig().Shell != "" || r.GetHistoryConfig().File != "" {
+		t.Error("untrusted project config changed execution or history settings")
+	}
+	if len(r.GetEnvProfiles()) != 0 || len(r.GetTargets()) != 0 {
+		t.Error("untrusted project config added env profiles or targets")
+	}
+}
+
+func TestTrustedProjectConfig(t *testing.T) {
+	for _, trusted := range []string{"repo", filepath.Join("repo", ProjectConfigName)} {
+		t.Run(trusted, func(t *testing.T) {
+			r, project, errs := loadProject(t, trusted)
+			if len(errs) > 0 {
+				t.Fatalf("LoadLayers() errors = %v", errs)
+			}
+			if !r.IsTrustedProject(project) {
+				t.Error("IsTrustedProject() = false for a listed project")
+			}
+			if r.GetExecutionConfig().Shell != "./bin/sh" || len(r.GetTargets()) != 1 {
+				t.Error("trusted project config was restricted")
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"bytes"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,26 +19,76 @@ var embeddedCommands embed.FS
 // Config represents the configuration file structure
 type Config struct {
 	Commands []Command `yaml:"commands" json:"commands"`
+	Runbooks []Runbook `yaml:"runbooks,omitempty" json:"runbooks,omitempty"`
+
+	// DangerRules overrides built-in danger rules by ID or adds new ones
+	DangerRules []DangerRule `yaml:"danger_rules,omitempty" json:"danger_rules,omitempty"`
+
+	// Placeholders declares shared placeholders (e.g. providers) for all commands in the file
+	Placeholders []Placeholder `yaml:"placeholders,omitempty" json:"placeholders,omitempty"`
+
+	// Categories declares custom categories used by the file's commands
+	Categories []string `yaml:"categories,omitempty" json:"categories,omitempty"`
+
+	// Disable removes commands and runbooks of earlier layers by template or ID
+	Disable []string `yaml:"disable,omitempty" json:"disable,omitempty"`
+
+	// History configures the persistent command history
+	History *HistoryConfig `yaml:"history,omitempty" json:"history,omitempty"`
+
+	// Frecency configures ranking suggestions by usage
+	Frecency *FrecencyConfig `yaml:"frecency,omitempty" json:"frecency,omitempty"`
+
+	// Execution configures command timeouts and how commands are stopped
+	Execution *ExecutionConfig `yaml:"execution,omitempty" json:"execution,omitempty"`
+
+	// Favorites pins templates to the top of the suggestions for empty input
+	Favorites []string `yaml:"favorites,omitempty" json:"favorites,omitempty"`
+
+	// FavoriteSets groups pinned commands into named sets for the favorites panel
+	FavoriteSets []FavoriteSet `yaml:"favorite_sets,omitempty" json:"favorite_sets,omitempty"`
+
+	// EnvProfiles defines environment profiles to switch between with Ctrl+E
+	EnvProfiles []EnvProfile `yaml:"env_profiles,omitempty" json:"env_profiles,omitempty"`
+
+	// Targets defines containers and remote hosts to run commands on (Ctrl+X)
+	Targets []TargetConfig `yaml:"targets,omitempty" json:"targets,omitempty"`
+
+	// TrustedProjects lists project directories whose .architerm.yaml may set
+	// anything; other project files are restricted. Read from system and user files.
+	TrustedProjects []string `yaml:"trusted_projects,omitempty" json:"trusted_projects,omitempty"`
 }
 
 // EmbeddedConfig represents the structure of embedded JSON files
 type EmbeddedConfig struct {
-	Category string `json:"category"`
-	Commands []struct {
-		Template    string   `json:"template"`
-		Description string   `json:"description"`
-		Tags        []string `json:"tags"`
+	Category     string        `json:"category"`
+	Placeholders []Placeholder `json:"placeholders"`
+	Commands     []struct {
+		ID           string        `json:"id"`
+		Template     string        `json:"template"`
+		Description  string        `json:"description"`
+		Tags         []string      `json:"tags"`
+		Placeholders []Placeholder `json:"placeholders"`
+		Danger       string        `json:"danger"`
 	} `json:"commands"`
+	Runbooks []Runbook `json:"runbooks"`
 }
 
 // LoadEmbeddedCommands loads all commands from embedded JSON files
 func LoadEmbeddedCommands() ([]Command, error) {
+	cmds, _, err := LoadEmbeddedPacks()
+	return cmds, err
+}
+
+// LoadEmbeddedPacks loads all commands and runbooks from embedded JSON files
+func LoadEmbeddedPacks() ([]Command, []Runbook, error) {
 	var allCommands []Command
+	var allRunbooks []Runbook
 
 	// Read all files from the embedded directory
 	entries, err := embeddedCommands.ReadDir("embedded")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read embedded commands directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to read embedded commands directory: %w", err)
 	}
 
 	for _, entry := range entries {
@@ -46,30 +98,53 @@ func LoadEmbeddedCommands() ([]Command, error) {
 
 		data, err := embeddedCommands.ReadFile("embedded/" + entry.Name())
 		if err != nil {
-			return nil, fmt.Errorf("failed to read embedded file %s: %w", entry.Name(), err)
+			return nil, nil, fmt.Errorf("failed to read embedded file %s: %w", entry.Name(), err)
 		}
 
 		var config EmbeddedConfig
 		if err := json.Unmarshal(data, &config); err != nil {
-			return nil, fmt.Errorf("failed to parse embedded file %s: %w", entry.Name(), err)
+			return nil, nil, fmt.Errorf("failed to parse embedded file %s: %w", entry.Name(), err)
 		}
 
 		// Convert to Command structs
+		source := "embedded/" + entry.Name()
+		packCommands := make([]Command, 0, len(config.Commands))
 		for _, cmd := range config.Commands {
-			allCommands = append(allCommands, Command{
-				Template:    cmd.Template,
-				Description: cmd.Description,
-				Category:    config.Category,
-				Tags:        cmd.Tags,
+			packCommands = append(packCommands, Command{
+				ID:           cmd.ID,
+				Template:     cmd.Template,
+				Description:  cmd.Description,
+				Category:     config.Category,
+				Tags:         cmd.Tags,
+				Placeholders: cmd.Placeholders,
+				Danger:       cmd.Danger,
+				Source:       source,
 			})
 		}
+		ApplyPlaceholderDefaults(packCommands, config.Placeholders)
+		allCommands = append(allCommands, packCommands...)
+
+		applyRunbookDefaults(config.Runbooks, config.Category, config.Placeholders)
+		for i := range config.Runbooks {
+			config.Runbooks[i].Source = source
+		}
+		allRunbooks = append(allRunbooks, config.Runbooks...)
 	}
 
-	return allCommands, nil
+	return allCommands, allRunbooks, nil
 }
 
 // LoadConfig loads commands from a YAML or JSON configuration file
 func LoadConfig(path string) ([]Command, error) {
+	config, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return config.Commands, nil
+}
+
+// LoadConfigFile loads a full YAML or JSON configuration file (commands and runbooks)
+func LoadConfigFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -85,34 +160,83 @@ func LoadConfig(path string) ([]Command, error) {
 		}
 	case ".json":
 		if err := json.Unmarshal(data, &config); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				line, column := offsetPosition(data, syntaxErr.Offset)
+				return nil, fmt.Errorf("failed to parse JSON config: line %d, column %d: %w", line, column, err)
+			}
 			return nil, fmt.Errorf("failed to parse JSON config: %w", err)
 		}
 	default:
 		return nil, fmt.Errorf("unsupported config format: %s (use .yaml, .yml, or .json)", ext)
 	}
 
-	return config.Commands, nil
+	ApplyPlaceholderDefaults(config.Commands, config.Placeholders)
+	applyRunbookDefaults(config.Runbooks, "", config.Placeholders)
+	if err := checkEnumValues(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// checkEnumValues rejects enum placeholders without allowed values, which
+// would accept any value. Values may come from the file's shared placeholders.
+func checkEnumValues(config *Config) error {
+	for _, cmd := range config.Commands {
+		for _, p := range cmd.Placeholders {
+			if p.Type == TypeEnum && len(p.Values) == 0 {
+				return fmt.Errorf("command %q: enum placeholder %s has no values", cmd.Template, p.Name)
+			}
+		}
+	}
+	for _, rb := range config.Runbooks {
+		for _, p := range rb.Placeholders {
+			if p.Type == TypeEnum && len(p.Values) == 0 {
+				return fmt.Errorf("runbook %q: enum placeholder %s has no values", rb.ID, p.Name)
+			}
+		}
+	}
+	return nil
 }
 
 // GetDefaultConfigPath returns the default config file path
 func GetDefaultConfigPath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
+	dir := GetUserConfigDir()
+	if dir == "" {
 		return ""
 	}
-	return filepath.Join(home, ".config", "architerm", "commands.yaml")
+	return filepath.Join(dir, "commands.yaml")
 }
 
 // LoadUserConfig attempts to load user configuration from default location
 func LoadUserConfig() ([]Command, error) {
-	configPath := GetDefaultConfigPath()
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// Try JSON as fallback
-		jsonPath := strings.TrimSuffix(configPath, ".yaml") + ".json"
-		if _, err := os.Stat(jsonPath); os.IsNotExist(err) {
-			return nil, nil // No user config, that's fine
-		}
-		configPath = jsonPath
+	config, err := LoadUserConfigFile()
+	if err != nil || config == nil {
+		return nil, err
+	}
+	return config.Commands, nil
+}
+
+// LoadUserConfigFile attempts to load the full user configuration from default location.
+// Returns nil without error if there is no user config.
+func LoadUserConfigFile() (*Config, error) {
+	layer := configFileLayer(LayerUser, GetUserConfigDir())
+	if !layer.Exists {
+		return nil, nil // No user config, that's fine
+	}
+	return LoadConfigFile(layer.Path)
+}
+
+// MarshalYAML encodes v as YAML with the two-space indent used in config files
+func MarshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
 	}
-	return LoadConfig(configPath)
+	return buf.Bytes(), nil
 }
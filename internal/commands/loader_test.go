@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFileEnumValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			"values declared",
+			`commands:
+  - template: kubectl config use-context CONTEXT
+    placeholders:
+      - name: CONTEXT
+        type: enum
+        values: [dev, prod]
+`,
+			"",
+		},
+		{
+			"values from shared placeholders",
+			`placeholders:
+  - name: CONTEXT
+    values: [dev, prod]
+commands:
+  - template: kubectl config use-context CONTEXT
+    placeholders:
+      - name: CONTEXT
+        type: enum
+`,
+			"",
+		},
+		{
+			"command without values",
+			`commands:
+  - template: kubectl config use-context CONTEXT
+    placeholders:
+      - name: CONTEXT
+        type: enum
+`,
+			"enum placeholder CONTEXT has no values",
+		},
+		{
+			"runbook without values",
+			`runbooks:
+  - id: switch
+    name: Switch
+    steps:
+      - name: use
+        command: kubectl config use-context CONTEXT
+    placeholders:
+      - name: CONTEXT
+        type: enum
+`,
+			`runbook "switch": enum placeholder CONTEXT has no values`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "commands.yaml")
+			writeFile(t, path, tt.config)
+			_, err := LoadConfigFile(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadConfigFile() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfigFile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,468 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Placeholder describes a value that has to be supplied before a template can run
+type Placeholder struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Default     string `yaml:"default,omitempty" json:"default,omitempty"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+
+	// Validation
+	Type    string   `yaml:"type,omitempty" json:"type,omitempty"`       // One of PlaceholderTypes, "string" if empty
+	Pattern string   `yaml:"pattern,omitempty" json:"pattern,omitempty"` // Regex the whole value must match
+	Values  []string `yaml:"values,omitempty" json:"values,omitempty"`   // Allowed values
+
+	// Provider lists live candidate values for the placeholder
+	Provider *Provider `yaml:"provider,omitempty" json:"provider,omitempty"`
+}
+
+// Placeholder types
+const (
+	TypeString   = "string"
+	TypeInt      = "int"
+	TypePort     = "port"
+	TypePath     = "path"
+	TypeEnum     = "enum"
+	TypeDuration = "duration"
+)
+
+// PlaceholderTypes lists the supported placeholder types
+var PlaceholderTypes = []string{TypeString, TypeInt, TypePort, TypePath, TypeEnum, TypeDuration}
+
+// IsValidType reports whether t is a supported placeholder type (empty means string)
+func IsValidType(t string) bool {
+	if t == "" {
+		return true
+	}
+	for _, known := range PlaceholderTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a value against the placeholder's type, pattern and allowed values
+func (p Placeholder) Validate(value string) error {
+	if strings.TrimSpace(value) == "" {
+		if p.Required {
+			return fmt.Errorf("required")
+		}
+		return nil
+	}
+
+	switch p.Type {
+	case TypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+	case TypePort:
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("must be a port number (1-65535)")
+		}
+	case TypePath:
+		if strings.ContainsAny(value, "\x00\n") {
+			return fmt.Errorf("must be a valid path")
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("must be a duration (e.g. 30s, 5m, 1h)")
+		}
+	case TypeEnum, TypeString, "":
+	default:
+		return fmt.Errorf("unknown type %q", p.Type)
+	}
+
+	if len(p.Values) > 0 {
+		allowed := false
+		for _, v := range p.Values {
+			if v == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("must be one of: %s", strings.Join(p.Values, ", "))
+		}
+	}
+
+	if p.Pattern != "" {
+		re, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q", p.Pattern)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("must match %s", p.Pattern)
+		}
+	}
+
+	return nil
+}
+
+// Provider is a command whose output lines are offered as placeholder values.
+// The command may reference other placeholders (e.g. NAMESPACE), which are
+// filled in with the values entered before it runs.
+type Provider struct {
+	Command string `yaml:"command" json:"command"`
+	TTL     string `yaml:"ttl,omitempty" json:"ttl,omitempty"` // Cache lifetime, e.g. "30s"
+}
+
+// DefaultProviderTTL is how long provider results are cached when no TTL is set
+const DefaultProviderTTL = 30 * time.Second
+
+// CacheTTL returns the parsed cache lifetime of the provider
+func (p *Provider) CacheTTL() time.Duration {
+	if p.TTL == "" {
+		return DefaultProviderTTL
+	}
+	ttl, err := time.ParseDuration(p.TTL)
+	if err != nil || ttl < 0 {
+		return DefaultProviderTTL
+	}
+	return ttl
+}
+
+// Dependencies returns the names of the given placeholders referenced by the provider command
+func (p *Provider) Dependencies(placeholders []Placeholder) []string {
+	var deps []string
+	for _, other := range placeholders {
+		if tokenIndex(p.Command, other.Name) >= 0 {
+			deps = append(deps, other.Name)
+		}
+	}
+	return deps
+}
+
+// literalUppercaseWords are uppercase tokens used verbatim by common tools
+// (HTTP methods, iptables chains, netstat states, git refs)
+var literalUppercaseWords = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true,
+	"INPUT": true, "OUTPUT": true, "FORWARD": true, "ACCEPT": true, "DROP": true, "REJECT": true,
+	"LISTEN": true, "ESTABLISHED": true,
+}
+
+// DetectPlaceholders returns the UPPERCASE placeholder tokens of a template in order of appearance
+func DetectPlaceholders(template string) []string {
+	seen := make(map[string]bool)
+	var names []string
+
+	i := 0
+	for i < len(template) {
+		if !isUpper(template[i]) || (i > 0 && isWordChar(template[i-1])) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(template) && (isUpper(template[j]) || isDigit(template[j]) || template[j] == '_') {
+			j++
+		}
+		// Reject tokens glued to lowercase text (e.g. "Checking")
+		if j < len(template) && isWordChar(template[j]) {
+			for j < len(template) && isWordChar(template[j]) {
+				j++
+			}
+			i = j
+			continue
+		}
+		token := template[i:j]
+		if len(token) >= 2 && !literalUppercaseWords[token] && !seen[token] {
+			seen[token] = true
+			names = append(names, token)
+		}
+		i = j
+	}
+
+	return names
+}
+
+// GetPlaceholders returns the placeholders of the command in template order.
+// Declared placeholders take precedence over detected ones; detected
+// placeholders without a declaration are always required.
+func (c Command) GetPlaceholders() []Placeholder {
+	byName := make(map[string]Placeholder)
+	for _, name := range DetectPlaceholders(c.Template) {
+		byName[name] = Placeholder{Name: name, Required: true}
+	}
+	for _, p := range c.Placeholders {
+		if p.Name != "" && tokenIndex(c.Template, p.Name) >= 0 {
+			byName[p.Name] = p
+		}
+	}
+
+	placeholders := make([]Placeholder, 0, len(byName))
+	for _, p := range byName {
+		placeholders = append(placeholders, p)
+	}
+	sort.Slice(placeholders, func(i, j int) bool {
+		return tokenIndex(c.Template, placeholders[i].Name) < tokenIndex(c.Template, placeholders[j].Name)
+	})
+	return placeholders
+}
+
+// ApplyPlaceholderDefaults merges pack-level placeholder declarations into
+// every command whose template uses them. Fields declared on the command win.
+func ApplyPlaceholderDefaults(cmds []Command, defaults []Placeholder) {
+	if len(defaults) == 0 {
+		return
+	}
+	for i := range cmds {
+		cmd := &cmds[i]
+		for _, def := range defaults {
+			if tokenIndex(cmd.Template, def.Name) < 0 {
+				continue
+			}
+			merged := false
+			for j := range cmd.Placeholders {
+				if cmd.Placeholders[j].Name == def.Name {
+					cmd.Placeholders[j] = mergePlaceholder(def, cmd.Placeholders[j])
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				cmd.Placeholders = append(cmd.Placeholders, def)
+			}
+		}
+	}
+}
+
+// mergePlaceholder overlays the non-empty fields of override onto base
+func mergePlaceholder(base, override Placeholder) Placeholder {
+	result := base
+	if override.Description != "" {
+		result.Description = override.Description
+	}
+	if override.Default != "" {
+		result.Default = override.Default
+	}
+	if override.Required {
+		result.Required = true
+	}
+	if override.Type != "" {
+		result.Type = override.Type
+	}
+	if override.Pattern != "" {
+		result.Pattern = override.Pattern
+	}
+	if len(override.Values) > 0 {
+		result.Values = override.Values
+	}
+	if override.Provider != nil {
+		result.Provider = override.Provider
+	}
+	return result
+}
+
+// OrderByDependencies reorders placeholders so that those referenced by a
+// provider command come before the placeholder that depends on them
+func OrderByDependencies(placeholders []Placeholder) []Placeholder {
+	ordered := make([]Placeholder, 0, len(placeholders))
+	placed := make(map[string]bool)
+	remaining := placeholders
+
+	for len(remaining) > 0 {
+		var next []Placeholder
+		for _, p := range remaining {
+			ready := true
+			if p.Provider != nil {
+				for _, dep := range p.Provider.Dependencies(remaining) {
+					if dep != p.Name && !placed[dep] {
+						ready = false
+						break
+					}
+				}
+			}
+			if ready {
+				ordered = append(ordered, p)
+				placed[p.Name] = true
+			} else {
+				next = append(next, p)
+			}
+		}
+		if len(next) == len(remaining) {
+			// Dependency cycle: keep the template order for the rest
+			ordered = append(ordered, next...)
+			break
+		}
+		remaining = next
+	}
+
+	return ordered
+}
+
+// PresentPlaceholders filters placeholders down to those still present in the input
+func PresentPlaceholders(input string, placeholders []Placeholder) []Placeholder {
+	var present []Placeholder
+	for _, p := range placeholders {
+		if tokenIndex(input, p.Name) >= 0 {
+			present = append(present, p)
+		}
+	}
+	return present
+}
+
+// Span is a byte range [Start, End) within a string
+type Span struct {
+	Start int
+	End   int
+}
+
+// FindPlaceholderSpans returns the positions of all whole-token occurrences of
+// the named placeholders in the input, ordered by position
+func FindPlaceholderSpans(input string, names []string) []Span {
+	var spans []Span
+	for _, name := range names {
+		offset := 0
+		for offset < len(input) {
+			idx := tokenIndex(input[offset:], name)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			spans = append(spans, Span{Start: start, End: start + len(name)})
+			offset = start + len(name)
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Start < spans[j].Start
+	})
+	return spans
+}
+
+// FillPlaceholders replaces every placeholder token in the input with its value.
+// Tokens with an empty value are removed together with one adjacent space.
+// Substitution is done in a single pass, so values containing another
+// placeholder's name are inserted verbatim.
+func FillPlaceholders(input string, values map[string]string) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, span := range FindPlaceholderSpans(input, names) {
+		value := values[input[span.Start:span.End]]
+		start := span.Start
+		if value == "" && start > last && input[start-1] == ' ' && (span.End == len(input) || input[span.End] == ' ') {
+			start--
+		}
+		sb.WriteString(input[last:start])
+		sb.WriteString(value)
+		last = span.End
+	}
+	sb.WriteString(input[last:])
+	return sb.String()
+}
+
+// MatchTemplate reports whether input is the template with its placeholders
+// filled in, and returns the value given for each placeholder. Optional
+// placeholders removed together with their space (empty values) match as "".
+func MatchTemplate(template string, placeholders []Placeholder, input string) (map[string]string, bool) {
+	names := make([]string, 0, len(placeholders))
+	required := make(map[string]bool, len(placeholders))
+	for _, p := range placeholders {
+		names = append(names, p.Name)
+		required[p.Name] = p.Required
+	}
+	spans := FindPlaceholderSpans(template, names)
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	last := 0
+	for _, span := range spans {
+		literal := template[last:span.Start]
+		switch {
+		case required[template[span.Start:span.End]]:
+			sb.WriteString(regexp.QuoteMeta(literal))
+			sb.WriteString("(.+?)")
+		case span.Start > last && template[span.Start-1] == ' ' && (span.End == len(template) || template[span.End] == ' '):
+			sb.WriteString(regexp.QuoteMeta(literal[:len(literal)-1]))
+			sb.WriteString("(?: (.*?))?")
+		default:
+			sb.WriteString(regexp.QuoteMeta(literal))
+			sb.WriteString("(.*?)")
+		}
+		last = span.End
+	}
+	sb.WriteString(regexp.QuoteMeta(template[last:]))
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, false
+	}
+	match := re.FindStringSubmatch(input)
+	if match == nil {
+		return nil, false
+	}
+
+	values := make(map[string]string, len(spans))
+	for i, span := range spans {
+		name := template[span.Start:span.End]
+		if prev, ok := values[name]; ok && prev != match[i+1] {
+			// A repeated placeholder must have the same value everywhere
+			return nil, false
+		}
+		values[name] = match[i+1]
+	}
+	return values, true
+}
+
+// ValidateFilled checks the placeholder values of an input built from the
+// command's template. Inputs that no longer match the template are not checked.
+func (c Command) ValidateFilled(input string) error {
+	placeholders := c.GetPlaceholders()
+	values, ok := MatchTemplate(c.Template, placeholders, input)
+	if !ok {
+		return nil
+	}
+	for _, p := range placeholders {
+		if err := p.Validate(values[p.Name]); err != nil {
+			return fmt.Errorf("%s %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// tokenIndex returns the index of the first whole-token occurrence of name in s, or -1
+func tokenIndex(s, name string) int {
+	if name == "" {
+		return -1
+	}
+	offset := 0
+	for {
+		idx := strings.Index(s[offset:], name)
+		if idx < 0 {
+			return -1
+		}
+		start := offset + idx
+		end := start + len(name)
+		if (start == 0 || !isWordChar(s[start-1])) && (end == len(s) || !isWordChar(s[end])) {
+			return start
+		}
+		offset = start + 1
+	}
+}
+
+func isWordChar(c byte) bool {
+	return isUpper(c) || isDigit(c) || c == '_' || (c >= 'a' && c <= 'z')
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
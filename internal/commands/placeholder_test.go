@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectPlaceholders(t *testing.T) {
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"kubectl logs POD -n NAMESPACE", []string{"POD", "NAMESPACE"}},
+		{"docker run IMAGE:TAG", []string{"IMAGE", "TAG"}},
+		{"cp SRC SRC.bak", []string{"SRC"}},
+		{"tail -f LOG_FILE2", []string{"LOG_FILE2"}},
+		{"echo Checking status", nil},
+		{"echo myVAR", nil},
+		{"curl -X GET URL", []string{"URL"}},
+		{"iptables -A INPUT -j DROP", nil},
+		{"echo A", nil},
+		{"ls -la", nil},
+	}
+	for _, tt := range tests {
+		if got := DetectPlaceholders(tt.template); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DetectPlaceholders(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestGetPlaceholders(t *testing.T) {
+	cmd := Command{
+		Template: "docker logs --tail LINES CONTAINER",
+		Placeholders: []Placeholder{
+			{Name: "CONTAINER", Description: "Container name"},
+			{Name: "LINES", Default: "100"},
+			{Name: "UNUSED", Default: "x"},
+		},
+	}
+	want := []Placeholder{
+		{Name: "LINES", Default: "100"},
+		{Name: "CONTAINER", Description: "Container name"},
+	}
+	if got := cmd.GetPlaceholders(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetPlaceholders() = %+v, want %+v", got, want)
+	}
+
+	detected := Command{Template: "ssh HOST"}.GetPlaceholders()
+	if want := []Placeholder{{Name: "HOST", Required: true}}; !reflect.DeepEqual(detected, want) {
+		t.Errorf("GetPlaceholders() = %+v, want %+v", detected, want)
+	}
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		values map[string]string
+		want   string
+	}{
+		{"all", "kubectl logs POD -n NS", map[string]string{"POD": "web-1", "NS": "prod"}, "kubectl logs web-1 -n prod"},
+		{"image and tag", "docker run IMAGE:TAG", map[string]string{"IMAGE": "nginx", "TAG": "1.25"}, "docker run nginx:1.25"},
+		{"repeated", "cp SRC SRC.bak", map[string]string{"SRC": "a.txt"}, "cp a.txt a.txt.bak"},
+		{"lowercase words untouched", "echo PODS POD", map[string]string{"POD": "x"}, "echo PODS x"},
+		{"missing value", "ssh HOST PORT", map[string]string{"HOST": "web-1"}, "ssh web-1 PORT"},
+		{"empty in the middle", "ls FLAGS DIR", map[string]string{"FLAGS": "", "DIR": "/tmp"}, "ls /tmp"},
+		{"empty at the end", "git log LIMIT", map[string]string{"LIMIT": ""}, "git log"},
+		{"empty glued to text", "echo X=VALUE", map[string]string{"VALUE": ""}, "echo X="},
+		{"value naming another placeholder", "git commit -m MSG --author WHO", map[string]string{"MSG": "'ping WHO'", "WHO": "me"},
+			"git commit -m 'ping WHO' --author me"},
+		{"all empty", "ls FLAGS DIR", map[string]string{"FLAGS": "", "DIR": ""}, "ls"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FillPlaceholders(tt.input, tt.values); got != tt.want {
+				t.Errorf("FillPlaceholders(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceholderValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Placeholder
+		value   string
+		wantErr bool
+	}{
+		{"string", Placeholder{}, "anything goes", false},
+		{"optional empty", Placeholder{Type: TypeInt}, "", false},
+		{"required empty", Placeholder{Required: true}, "  ", true},
+		{"int", Placeholder{Type: TypeInt}, "-42", false},
+		{"int with text", Placeholder{Type: TypeInt}, "42x", true},
+		{"int fraction", Placeholder{Type: TypeInt}, "1.5", true},
+		{"port", Placeholder{Type: TypePort}, "8080", false},
+		{"port zero", Placeholder{Type: TypePort}, "0", true},
+		{"port too high", Placeholder{Type: TypePort}, "65536", true},
+		{"port name", Placeholder{Type: TypePort}, "http", true},
+		{"path", Placeholder{Type: TypePath}, "/var/log/app.log", false},
+		{"path with newline", Placeholder{Type: TypePath}, "a\nb", true},
+		{"duration", Placeholder{Type: TypeDuration}, "1h30m", false},
+		{"duration without unit", Placeholder{Type: TypeDuration}, "30", true},
+		{"enum", Placeholder{Type: TypeEnum, Values: []string{"dev", "prod"}}, "prod", false},
+		{"enum not allowed", Placeholder{Type: TypeEnum, Values: []string{"dev", "prod"}}, "staging", true},
+		{"enum is exact", Placeholder{Type: TypeEnum, Values: []string{"dev", "prod"}}, "Prod", true},
+		{"pattern", Placeholder{Pattern: `[a-z0-9-]+`}, "web-1", false},
+		{"pattern matches whole value", Placeholder{Pattern: `[a-z]+`}, "web-1", true},
+		{"pattern alternation is anchored", Placeholder{Pattern: `a|b`}, "ab", true},
+		{"invalid pattern", Placeholder{Pattern: `(`}, "x", true},
+		{"unknown type", Placeholder{Type: "float"}, "1.5", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.p.Validate(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchTemplate(t *testing.T) {
+	placeholders := []Placeholder{{Name: "IMAGE", Required: true}, {Name: "TAG"}, {Name: "FLAGS"}}
+	tests := []struct {
+		name     string
+		template string
+		input    string
+		want     map[string]string
+		ok       bool
+	}{
+		{"filled", "docker run IMAGE:TAG", "docker run nginx:1.25", map[string]string{"IMAGE": "nginx", "TAG": "1.25"}, true},
+		{"value with spaces", "docker run -e 'FLAGS' IMAGE", "docker run -e 'A=1 B=2' nginx",
+			map[string]string{"FLAGS": "A=1 B=2", "IMAGE": "nginx"}, true},
+		{"optional removed with its space", "docker run FLAGS IMAGE", "docker run nginx",
+			map[string]string{"FLAGS": "", "IMAGE": "nginx"}, true},
+		{"optional given", "docker run FLAGS IMAGE", "docker run --rm nginx",
+			map[string]string{"FLAGS": "--rm", "IMAGE": "nginx"}, true},
+		{"required removed", "docker run FLAGS IMAGE", "docker run", nil, false},
+		{"repeated", "cp IMAGE IMAGE.bak", "cp a a.bak", map[string]string{"IMAGE": "a"}, true},
+		{"repeated with different values", "cp IMAGE IMAGE.bak", "cp a b.bak", nil, false},
+		{"edited away", "docker run IMAGE:TAG", "docker pull nginx:1.25", nil, false},
+		{"unfilled", "docker run IMAGE:TAG", "docker run IMAGE:TAG", map[string]string{"IMAGE": "IMAGE", "TAG": "TAG"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MatchTemplate(tt.template, placeholders, tt.input)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchTemplate(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestValidateFilled(t *testing.T) {
+	cmd := Command{
+		Template: "kubectl port-forward POD PORT:80",
+		Placeholders: []Placeholder{
+			{Name: "PORT", Type: TypePort, Required: true},
+		},
+	}
+	if err := cmd.ValidateFilled("kubectl port-forward web-1 8080:80"); err != nil {
+		t.Errorf("ValidateFilled() error = %v", err)
+	}
+	if err := cmd.ValidateFilled("kubectl port-forward web-1 http:80"); err == nil {
+		t.Error("ValidateFilled() accepted a port that is not a number")
+	}
+	if err := cmd.ValidateFilled("kubectl get pods"); err != nil {
+		t.Errorf("ValidateFilled() checked an input edited away from the template: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/duladissa/architerm/internal/session"
+)
+
+// EnvProfile is a named set of environment variables for the session's
+// commands, such as dev, staging or prod
+type EnvProfile struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// Env sets variables, overriding those from EnvFiles
+	Env map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// EnvFiles are .env files to load, relative to the config file
+	EnvFiles []string `yaml:"env_files,omitempty" json:"env_files,omitempty"`
+
+	// Protected highlights the profile in the status bar while it is active
+	Protected bool `yaml:"protected,omitempty" json:"protected,omitempty"`
+
+	// Source is the config layer the profile came from
+	Source string `yaml:"-" json:"-"`
+}
+
+// upsertEnvProfile replaces the profile with the same name, or appends it
+func (r *Registry) upsertEnvProfile(profile EnvProfile) {
+	for i := range r.envProfiles {
+		if strings.EqualFold(r.envProfiles[i].Name, profile.Name) {
+			r.envProfiles[i] = profile
+			return
+		}
+	}
+	r.envProfiles = append(r.envProfiles, profile)
+}
+
+// GetEnvProfiles returns the configured environment profiles
+func (r *Registry) GetEnvProfiles() []EnvProfile {
+	return r.envProfiles
+}
+
+// EnvFilePaths returns the profile's env files, resolved against the
+// directory of the config file that defined them
+func (p EnvProfile) EnvFilePaths() []string {
+	paths := make([]string, len(p.EnvFiles))
+	for i, file := range p.EnvFiles {
+		path := session.ExpandHome(file)
+		if !filepath.IsAbs(path) && p.Source != "" {
+			path = filepath.Join(filepath.Dir(p.Source), path)
+		}
+		paths[i] = path
+	}
+	return paths
+}
+
+// Load reads the profile's env files and variables into a session profile
+func (p EnvProfile) Load() (*session.Profile, error) {
+	vars := make(map[string]string)
+	for _, path := range p.EnvFilePaths() {
+		fileVars, err := session.LoadEnvFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+		for name, value := range fileVars {
+			vars[name] = value
+		}
+	}
+	for name, value := range p.Env {
+		vars[name] = value
+	}
+	return &session.Profile{Name: p.Name, Vars: vars, Protected: p.Protected}, nil
+}
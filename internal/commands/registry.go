@@ -1,28 +1,75 @@
 package commands
 
 import (
+	"fmt"
 	"log"
+	"path/filepath"
 	"sort"
 	"strings"
 )
 
 // Command represents a single command template
 type Command struct {
+	ID          string   `yaml:"id,omitempty" json:"id,omitempty"` // Derived from the template if not set
 	Template    string   `yaml:"template" json:"template"`
 	Description string   `yaml:"description" json:"description"`
 	Category    string   `yaml:"category" json:"category"`
 	Tags        []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// Placeholders declares the values to collect before running the template.
+	// UPPERCASE tokens in the template are detected even when not declared.
+	Placeholders []Placeholder `yaml:"placeholders,omitempty" json:"placeholders,omitempty"`
+
+	// Danger overrides the danger rules for this template (none, caution or critical)
+	Danger string `yaml:"danger,omitempty" json:"danger,omitempty"`
+
+	// Interactive runs the command on the real terminal; unset means detect it
+	Interactive *bool `yaml:"interactive,omitempty" json:"interactive,omitempty"`
+
+	// Timeout stops the command after it has run this long, e.g. "30s",
+	// overriding the global execution timeout ("0" means no limit)
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// Shell runs the command in another shell than the configured one, e.g.
+	// "bash" for [[ ]] tests, or "none" to run it without a shell
+	Shell string `yaml:"shell,omitempty" json:"shell,omitempty"`
+
+	// Source is the config layer the command was loaded from
+	Source string `yaml:"-" json:"-"`
 }
 
-// Registry holds all registered commands
+// Registry holds all registered commands and runbooks
 type Registry struct {
-	commands []Command
+	commands    []Command
+	runbooks    []Runbook
+	dangerRules []DangerRule
+	disabled    []Disabled
+	history     HistoryConfig
+	frecency    FrecencyConfig
+	execution   ExecutionConfig
+
+	favorites       []string
+	favoriteSources []string
+	favoriteSets    []FavoriteSet
+
+	envProfiles []EnvProfile
+	targets     []TargetConfig
+
+	trustedProjects []string
+}
+
+// Disabled records a command or runbook removed by a later config layer
+type Disabled struct {
+	Key    string // Template or ID that was disabled
+	Source string // Layer that disabled it
+	Count  int    // Number of entries removed
 }
 
 // NewRegistry creates a new command registry with default commands
 func NewRegistry() *Registry {
 	r := &Registry{
-		commands: make([]Command, 0),
+		commands:    make([]Command, 0),
+		dangerRules: DefaultDangerRules(),
 	}
 	r.loadDefaults()
 	return r
@@ -30,17 +77,246 @@ func NewRegistry() *Registry {
 
 // loadDefaults loads all built-in commands from embedded JSON files
 func (r *Registry) loadDefaults() {
-	embeddedCmds, err := LoadEmbeddedCommands()
+	embeddedCmds, embeddedRunbooks, err := LoadEmbeddedPacks()
 	if err != nil {
 		log.Printf("Warning: failed to load embedded commands: %v", err)
 		return
 	}
-	r.commands = append(r.commands, embeddedCmds...)
+	for _, cmd := range embeddedCmds {
+		r.upsertCommand(cmd)
+	}
+	r.runbooks = append(r.runbooks, embeddedRunbooks...)
+}
+
+// LoadLayers applies the config files of the given layers in order.
+// Layers that fail to load are skipped and their errors returned.
+func (r *Registry) LoadLayers(layers []Layer) []error {
+	var errs []error
+	for _, layer := range layers {
+		if !layer.Exists {
+			continue
+		}
+		config, err := LoadConfigFile(layer.Path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", layer.Path, err))
+			continue
+		}
+		if layer.Name == LayerProject && !r.IsTrustedProject(layer.Path) {
+			if ignored := restrictProjectConfig(config); len(ignored) > 0 {
+				errs = append(errs, fmt.Errorf("%s: untrusted project config, ignored %s (add %s to trusted_projects in the user config)",
+					layer.Path, strings.Join(ignored, ", "), filepath.Dir(layer.Path)))
+			}
+		}
+		r.ApplyConfig(config, layer)
+	}
+	return errs
+}
+
+// ApplyConfig merges a layer's config file on top of the registry. Entries
+// listed under disable are removed first; commands then replace existing ones
+// with the same template or ID, runbooks replace those with the same ID, and
+// danger rules are merged by ID.
+func (r *Registry) ApplyConfig(config *Config, layer Layer) {
+	source := layer.Path
+	for _, key := range config.Disable {
+		r.disable(key, source)
+	}
+	for _, cmd := range config.Commands {
+		cmd.Source = source
+		r.upsertCommand(cmd)
+	}
+	for _, rb := range config.Runbooks {
+		rb.Source = source
+		r.upsertRunbook(rb)
+	}
+	rules := make([]DangerRule, len(config.DangerRules))
+	for i, rule := range config.DangerRules {
+		rule.Source = source
+		rules[i] = rule
+	}
+	r.dangerRules = MergeDangerRules(r.dangerRules, rules, layer.CanLowerDanger())
+	r.history = mergeHistoryConfig(r.history, config.History)
+	r.frecency = mergeFrecencyConfig(r.frecency, config.Frecency)
+	r.execution = mergeExecutionConfig(r.execution, config.Execution)
+	r.addFavorites(config.Favorites, source)
+	for _, set := range config.FavoriteSets {
+		set.Source = source
+		r.upsertFavoriteSet(set)
+	}
+	for _, profile := range config.EnvProfiles {
+		profile.Source = source
+		r.upsertEnvProfile(profile)
+	}
+	for _, target := range config.Targets {
+		target.Source = source
+		r.upsertTarget(target)
+	}
+	if layer.Name == LayerSystem || layer.Name == LayerUser {
+		r.trustedProjects = append(r.trustedProjects, config.TrustedProjects...)
+	}
+}
+
+// upsertCommand replaces the command with the same template or ID, or appends it
+func (r *Registry) upsertCommand(cmd Command) {
+	for i := range r.commands {
+		existing := &r.commands[i]
+		if existing.Template == cmd.Template || (cmd.ID != "" && existing.ID == cmd.ID) {
+			*existing = mergeCommand(*existing, cmd)
+			return
+		}
+	}
+	if cmd.ID == "" {
+		cmd.ID = CommandID(cmd.Template)
+	}
+	r.commands = append(r.commands, cmd)
+}
+
+// mergeCommand overlays the non-empty fields of override onto base
+func mergeCommand(base, override Command) Command {
+	result := base
+	if override.ID != "" {
+		result.ID = override.ID
+	}
+	if override.Template != "" {
+		result.Template = override.Template
+	}
+	if override.Description != "" {
+		result.Description = override.Description
+	}
+	if override.Category != "" {
+		result.Category = override.Category
+	}
+	if len(override.Tags) > 0 {
+		result.Tags = override.Tags
+	}
+	if len(override.Placeholders) > 0 {
+		result.Placeholders = override.Placeholders
+	}
+	if override.Danger != "" {
+		result.Danger = override.Danger
+	}
+	if override.Interactive != nil {
+		result.Interactive = override.Interactive
+	}
+	if override.Timeout != "" {
+		result.Timeout = override.Timeout
+	}
+	if override.Shell != "" {
+		result.Shell = override.Shell
+	}
+	result.Source = override.Source
+	return result
+}
+
+// upsertRunbook replaces the runbook with the same ID, or appends it
+func (r *Registry) upsertRunbook(rb Runbook) {
+	if rb.ID != "" {
+		for i := range r.runbooks {
+			if r.runbooks[i].ID == rb.ID {
+				r.runbooks[i] = rb
+				return
+			}
+		}
+	}
+	r.runbooks = append(r.runbooks, rb)
+}
+
+// disable removes the commands and runbooks matching a template or ID
+func (r *Registry) disable(key, source string) {
+	count := 0
+
+	cmds := r.commands[:0]
+	for _, cmd := range r.commands {
+		if cmd.Template == key || cmd.ID == key {
+			count++
+			continue
+		}
+		cmds = append(cmds, cmd)
+	}
+	r.commands = cmds
+
+	runbooks := r.runbooks[:0]
+	for _, rb := range r.runbooks {
+		if rb.ID == key {
+			count++
+			continue
+		}
+		runbooks = append(runbooks, rb)
+	}
+	r.runbooks = runbooks
+
+	r.disabled = append(r.disabled, Disabled{Key: key, Source: source, Count: count})
+}
+
+// GetDisabled returns the entries disabled by config layers
+func (r *Registry) GetDisabled() []Disabled {
+	return r.disabled
+}
+
+// GetHistoryConfig returns the merged history settings
+func (r *Registry) GetHistoryConfig() HistoryConfig {
+	return r.history
+}
+
+// GetFrecencyConfig returns the merged frecency settings
+func (r *Registry) GetFrecencyConfig() FrecencyConfig {
+	return r.frecency
+}
+
+// GetExecutionConfig returns the merged execution settings
+func (r *Registry) GetExecutionConfig() ExecutionConfig {
+	return r.execution
+}
+
+// CommandID derives a stable ID from a command template,
+// e.g. "kubectl get pods -n NAMESPACE" becomes "kubectl-get-pods-n-namespace"
+func CommandID(template string) string {
+	var sb strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(template) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(c)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return sb.String()
 }
 
-// AddCommands adds custom commands to the registry
+// AddCommands adds custom commands to the registry, replacing those with the same template or ID
 func (r *Registry) AddCommands(cmds []Command) {
-	r.commands = append(r.commands, cmds...)
+	for _, cmd := range cmds {
+		r.upsertCommand(cmd)
+	}
+}
+
+// AddRunbooks adds custom runbooks to the registry
+func (r *Registry) AddRunbooks(runbooks []Runbook) {
+	r.runbooks = append(r.runbooks, runbooks...)
+}
+
+// GetRunbooks returns all runbooks
+func (r *Registry) GetRunbooks() []Runbook {
+	return r.runbooks
+}
+
+// AddDangerRules overrides or extends the danger rules
+func (r *Registry) AddDangerRules(rules []DangerRule) {
+	r.dangerRules = MergeDangerRules(r.dangerRules, rules, true)
+}
+
+// GetDangerRules returns the active danger rules
+func (r *Registry) GetDangerRules() []DangerRule {
+	return r.dangerRules
+}
+
+// AssessDanger checks a command (optionally built from template) against the danger rules
+func (r *Registry) AssessDanger(command string, template *Command) Danger {
+	return AssessDanger(command, template, r.dangerRules)
 }
 
 // GetAll returns all commands
@@ -48,6 +324,16 @@ func (r *Registry) GetAll() []Command {
 	return r.commands
 }
 
+// FindByTemplate returns the command with the given template, or nil if none matches
+func (r *Registry) FindByTemplate(template string) *Command {
+	for i := range r.commands {
+		if r.commands[i].Template == template {
+			return &r.commands[i]
+		}
+	}
+	return nil
+}
+
 // GetTemplates returns all command templates
 func (r *Registry) GetTemplates() []string {
 	templates := make([]string, len(r.commands))
@@ -57,34 +343,45 @@ func (r *Registry) GetTemplates() []string {
 	return templates
 }
 
-// Search finds commands matching the query
+// Search finds commands matching the query. Every word of the query must
+// appear in the template, description, category or one of the tags.
 func (r *Registry) Search(query string) []Command {
 	if query == "" {
 		return r.commands
 	}
 
 	query = strings.ToLower(query)
+	words := strings.Fields(query)
 	var matches []Command
 
 	for _, cmd := range r.commands {
-		template := strings.ToLower(cmd.Template)
-		desc := strings.ToLower(cmd.Description)
-		category := strings.ToLower(cmd.Category)
-
-		// Check if query matches template, description, or category
-		if strings.Contains(template, query) ||
-			strings.Contains(desc, query) ||
-			strings.Contains(category, query) {
-			matches = append(matches, cmd)
+		fields := []string{
+			strings.ToLower(cmd.Template),
+			strings.ToLower(cmd.Description),
+			strings.ToLower(cmd.Category),
 		}
-
-		// Check tags
 		for _, tag := range cmd.Tags {
-			if strings.Contains(strings.ToLower(tag), query) {
-				matches = append(matches, cmd)
+			fields = append(fields, strings.ToLower(tag))
+		}
+
+		// Check that each word matches template, description, category or tags
+		matched := true
+		for _, word := range words {
+			found := false
+			for _, field := range fields {
+				if strings.Contains(field, word) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				matched = false
 				break
 			}
 		}
+		if matched {
+			matches = append(matches, cmd)
+		}
 	}
 
 	// Sort by relevance (prefix matches first)
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"strings"
+)
+
+// Runbook is an ordered list of command steps run one after another.
+// Values captured from a step's output become variables for later steps;
+// remaining UPPERCASE tokens are collected as inputs before the first step.
+type Runbook struct {
+	ID          string        `yaml:"id" json:"id"`
+	Name        string        `yaml:"name" json:"name"`
+	Description string        `yaml:"description,omitempty" json:"description,omitempty"`
+	Category    string        `yaml:"category,omitempty" json:"category,omitempty"`
+	Steps       []RunbookStep `yaml:"steps" json:"steps"`
+
+	// Placeholders declares the runbook inputs (detected from the steps if not declared)
+	Placeholders []Placeholder `yaml:"placeholders,omitempty" json:"placeholders,omitempty"`
+
+	// Source is the config layer the runbook was loaded from
+	Source string `yaml:"-" json:"-"`
+}
+
+// RunbookStep is a single command of a runbook
+type RunbookStep struct {
+	Name            string    `yaml:"name" json:"name"`
+	Command         string    `yaml:"command" json:"command"`
+	Capture         []Capture `yaml:"capture,omitempty" json:"capture,omitempty"`
+	ContinueOnError bool      `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty"`
+}
+
+// Capture extracts a variable from a step's output using a regex or a JSONPath.
+// For a regex, the first capture group is used if present, else the whole match.
+type Capture struct {
+	Var      string `yaml:"var" json:"var"`
+	Regex    string `yaml:"regex,omitempty" json:"regex,omitempty"`
+	JSONPath string `yaml:"jsonpath,omitempty" json:"jsonpath,omitempty"`
+}
+
+// CapturedVars returns the names of all variables captured by the runbook's steps
+func (rb Runbook) CapturedVars() map[string]bool {
+	vars := make(map[string]bool)
+	for _, step := range rb.Steps {
+		for _, c := range step.Capture {
+			vars[c.Var] = true
+		}
+	}
+	return vars
+}
+
+// Inputs returns the placeholders that must be supplied before the runbook starts
+func (rb Runbook) Inputs() []Placeholder {
+	captured := rb.CapturedVars()
+	var inputs []Placeholder
+	for _, p := range rb.asCommand().GetPlaceholders() {
+		if !captured[p.Name] {
+			inputs = append(inputs, p)
+		}
+	}
+	return inputs
+}
+
+// asCommand views all step commands as one template so placeholder detection
+// and pack-level defaults apply to runbooks the same way as to commands
+func (rb Runbook) asCommand() Command {
+	steps := make([]string, len(rb.Steps))
+	for i, step := range rb.Steps {
+		steps[i] = step.Command
+	}
+	return Command{
+		Template:     strings.Join(steps, "\n"),
+		Category:     rb.Category,
+		Placeholders: rb.Placeholders,
+	}
+}
+
+// applyRunbookDefaults merges pack-level placeholder declarations into runbooks
+func applyRunbookDefaults(runbooks []Runbook, category string, defaults []Placeholder) {
+	for i := range runbooks {
+		if runbooks[i].Category == "" {
+			runbooks[i].Category = category
+		}
+		cmds := []Command{runbooks[i].asCommand()}
+		ApplyPlaceholderDefaults(cmds, defaults)
+		runbooks[i].Placeholders = cmds[0].Placeholders
+	}
+}
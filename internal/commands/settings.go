@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/frecency"
+	"github.com/duladissa/architerm/internal/history"
+	"github.com/duladissa/architerm/internal/session"
+)
+
+// HistoryConfig configures the persistent command history
+type HistoryConfig struct {
+	// Persist writes history to disk (default true)
+	Persist *bool `yaml:"persist,omitempty" json:"persist,omitempty"`
+
+	// MaxEntries is the number of commands kept (default 10000)
+	MaxEntries int `yaml:"max_entries,omitempty" json:"max_entries,omitempty"`
+
+	// Dedupe is the policy for repeated commands: none, consecutive (default) or all
+	Dedupe string `yaml:"dedupe,omitempty" json:"dedupe,omitempty"`
+
+	// File overrides the history file path
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+}
+
+// IsPersistent reports whether history should be written to disk
+func (c HistoryConfig) IsPersistent() bool {
+	return c.Persist == nil || *c.Persist
+}
+
+// GetMaxEntries returns the configured history size, or the default
+func (c HistoryConfig) GetMaxEntries() int {
+	if c.MaxEntries > 0 {
+		return c.MaxEntries
+	}
+	return history.DefaultMaxEntries
+}
+
+// GetFile returns the configured history file, or the default path
+func (c HistoryConfig) GetFile() string {
+	if c.File != "" {
+		return session.ExpandHome(c.File)
+	}
+	return history.DefaultPath()
+}
+
+// mergeHistoryConfig overlays the set fields of override onto base
+func mergeHistoryConfig(base HistoryConfig, override *HistoryConfig) HistoryConfig {
+	if override == nil {
+		return base
+	}
+	if override.Persist != nil {
+		base.Persist = override.Persist
+	}
+	if override.MaxEntries != 0 {
+		base.MaxEntries = override.MaxEntries
+	}
+	if override.Dedupe != "" {
+		base.Dedupe = override.Dedupe
+	}
+	if override.File != "" {
+		base.File = override.File
+	}
+	return base
+}
+
+// FrecencyConfig configures ranking suggestions by how often and how recently
+// they were used
+type FrecencyConfig struct {
+	// Enabled learns from usage (default true)
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Scope ranks by uses anywhere (global, the default), in the working
+	// directory (directory) or in the enclosing git repository (repo)
+	Scope string `yaml:"scope,omitempty" json:"scope,omitempty"`
+}
+
+// IsEnabled reports whether suggestions are ranked by usage
+func (c FrecencyConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// GetScope returns the configured scope mode, or global
+func (c FrecencyConfig) GetScope() string {
+	if c.Scope == "" {
+		return frecency.ModeGlobal
+	}
+	return c.Scope
+}
+
+// mergeFrecencyConfig overlays the set fields of override onto base
+func mergeFrecencyConfig(base FrecencyConfig, override *FrecencyConfig) FrecencyConfig {
+	if override == nil {
+		return base
+	}
+	if override.Enabled != nil {
+		base.Enabled = override.Enabled
+	}
+	if override.Scope != "" {
+		base.Scope = override.Scope
+	}
+	return base
+}
+
+// ExecutionConfig configures how long commands may run and how they are stopped
+type ExecutionConfig struct {
+	// Timeout stops commands that run longer, e.g. "10m" (default no limit)
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// InterruptGrace is how long a stopped command has to exit after SIGINT
+	// before it gets SIGTERM (default 2s)
+	InterruptGrace string `yaml:"interrupt_grace,omitempty" json:"interrupt_grace,omitempty"`
+
+	// TerminateGrace is how long it has after SIGTERM before it gets SIGKILL (default 3s)
+	TerminateGrace string `yaml:"terminate_grace,omitempty" json:"terminate_grace,omitempty"`
+
+	// Shell runs commands: sh, bash, zsh, fish, pwsh, cmd or a path to one,
+	// or "none" to run them without a shell (default sh, cmd on Windows)
+	Shell string `yaml:"shell,omitempty" json:"shell,omitempty"`
+
+	// LoginShell starts the shell as a login shell, reading profile files
+	LoginShell *bool `yaml:"login_shell,omitempty" json:"login_shell,omitempty"`
+
+	// InteractiveShell starts the shell in interactive mode, reading rc files
+	// and aliases
+	InteractiveShell *bool `yaml:"interactive_shell,omitempty" json:"interactive_shell,omitempty"`
+}
+
+// Options returns the settings as executor options, with the per-command
+// timeout and shell of template (if any) in place of the global ones
+func (c ExecutionConfig) Options(template *Command) executor.Options {
+	opts := executor.Options{
+		Timeout:        parseDuration(c.Timeout),
+		InterruptGrace: parseDuration(c.InterruptGrace),
+		TerminateGrace: parseDuration(c.TerminateGrace),
+		Shell: executor.Shell{
+			Program:     session.ExpandHome(c.Shell),
+			Login:       c.LoginShell != nil && *c.LoginShell,
+			Interactive: c.InteractiveShell != nil && *c.InteractiveShell,
+		},
+	}
+	if template != nil && template.Timeout != "" {
+		opts.Timeout = parseDuration(template.Timeout)
+	}
+	if template != nil && template.Shell != "" {
+		opts.Shell.Program = session.ExpandHome(template.Shell)
+	}
+	return opts
+}
+
+// parseDuration parses a configured duration; empty or invalid values are zero
+func parseDuration(value string) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// mergeExecutionConfig overlays the set fields of override onto base
+func mergeExecutionConfig(base ExecutionConfig, override *ExecutionConfig) ExecutionConfig {
+	if override == nil {
+		return base
+	}
+	if override.Timeout != "" {
+		base.Timeout = override.Timeout
+	}
+	if override.InterruptGrace != "" {
+		base.InterruptGrace = override.InterruptGrace
+	}
+	if override.TerminateGrace != "" {
+		base.TerminateGrace = override.TerminateGrace
+	}
+	if override.Shell != "" {
+		base.Shell = override.Shell
+	}
+	if override.LoginShell != nil {
+		base.LoginShell = override.LoginShell
+	}
+	if override.InteractiveShell != nil {
+		base.InteractiveShell = override.InteractiveShell
+	}
+	return base
+}
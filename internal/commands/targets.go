@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/session"
+)
+
+// TargetTypes are the types of execution targets that can be configured
+var TargetTypes = []string{executor.TargetDocker, executor.TargetSSH}
+
+// TargetConfig is an execution target to switch to with Ctrl+X: a Docker
+// container or a remote host. Commands run locally by default.
+type TargetConfig struct {
+	Name        string `yaml:"name" json:"name"`
+	Type        string `yaml:"type" json:"type"` // docker or ssh
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// Container is the name or ID of the container docker exec runs in
+	Container string `yaml:"container,omitempty" json:"container,omitempty"`
+
+	// Host is the [user@]host ssh connects to, and Port its port if not 22
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+	Port int    `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// Dir is the working directory on the target
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+	// Args are extra arguments for docker exec or ssh, e.g. ["-i", "~/.ssh/deploy"]
+	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
+
+	// Source is the config layer the target came from
+	Source string `yaml:"-" json:"-"`
+}
+
+// IsValidTargetType reports whether t is a known target type
+func IsValidTargetType(t string) bool {
+	for _, known := range TargetTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// upsertTarget replaces the target with the same name, or appends it
+func (r *Registry) upsertTarget(target TargetConfig) {
+	for i := range r.targets {
+		if strings.EqualFold(r.targets[i].Name, target.Name) {
+			r.targets[i] = target
+			return
+		}
+	}
+	r.targets = append(r.targets, target)
+}
+
+// GetTargets returns the configured execution targets
+func (r *Registry) GetTargets() []TargetConfig {
+	return r.targets
+}
+
+// Target returns the executor target for the config, or nil if its type is unknown
+func (c TargetConfig) Target() executor.Target {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = session.ExpandHome(arg)
+	}
+	switch c.Type {
+	case executor.TargetDocker:
+		return executor.NewDockerTarget(c.Name, c.Container, c.Dir, args)
+	case executor.TargetSSH:
+		return executor.NewSSHTarget(c.Name, c.Host, c.Port, c.Dir, args)
+	}
+	return nil
+}
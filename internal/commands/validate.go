@@ -0,0 +1,568 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/frecency"
+	"github.com/duladissa/architerm/internal/history"
+	"github.com/duladissa/architerm/internal/jsonpath"
+	"github.com/duladissa/architerm/internal/session"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a single finding of config validation
+type Problem struct {
+	Path    string
+	Line    int // 0 if unknown
+	Column  int // 0 if unknown
+	Message string
+}
+
+// String formats the problem as path:line:column: message
+func (p Problem) String() string {
+	switch {
+	case p.Line > 0 && p.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", p.Path, p.Line, p.Column, p.Message)
+	case p.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", p.Path, p.Line, p.Message)
+	default:
+		return fmt.Sprintf("%s: %s", p.Path, p.Message)
+	}
+}
+
+// placeholderNamePattern matches names that are detected as placeholders in templates
+var placeholderNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+
+// yamlLinePattern extracts the line number from yaml error messages
+var yamlLinePattern = regexp.MustCompile(`line (\d+): (.*)`)
+
+// parsedFile is a config file decoded for validation
+type parsedFile struct {
+	path   string
+	root   *yaml.Node // Top-level mapping, nil if the file could not be parsed
+	config Config
+}
+
+// ValidateFiles checks config files for syntax errors, unknown fields, empty or
+// duplicate templates, unknown categories, bad placeholder declarations, bad
+// runbooks and danger rules, and unsupported extensions. Categories of the
+// embedded packs and those declared under "categories" in any of the files are known.
+func ValidateFiles(paths []string) []Problem {
+	var problems []Problem
+	var files []*parsedFile
+
+	known := make(map[string]bool)
+	if cmds, _, err := LoadEmbeddedPacks(); err == nil {
+		for _, cmd := range cmds {
+			known[strings.ToLower(cmd.Category)] = true
+		}
+	}
+
+	for _, path := range paths {
+		file, fileProblems := parseForValidation(path)
+		problems = append(problems, fileProblems...)
+		if file != nil {
+			files = append(files, file)
+			for _, category := range file.config.Categories {
+				known[strings.ToLower(category)] = true
+			}
+		}
+	}
+
+	for _, file := range files {
+		problems = append(problems, file.check(known)...)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return problems[i].Path < problems[j].Path
+		}
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems
+}
+
+// ValidateLayers validates the existing config files of the given layers
+func ValidateLayers(layers []Layer) []Problem {
+	var paths []string
+	for _, layer := range layers {
+		if layer.Exists {
+			paths = append(paths, layer.Path)
+		}
+	}
+	return ValidateFiles(paths)
+}
+
+// parseForValidation reads and decodes a config file, reporting syntax,
+// unknown field and type problems with their positions
+func parseForValidation(path string) (*parsedFile, []Problem) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return nil, []Problem{{Path: path, Message: fmt.Sprintf("unsupported config format %q (use .yaml, .yml, or .json)", ext)}}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, []Problem{{Path: path, Message: err.Error()}}
+	}
+
+	if ext == ".json" {
+		var raw interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			problem := Problem{Path: path, Message: err.Error()}
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				problem.Line, problem.Column = offsetPosition(data, syntaxErr.Offset)
+			}
+			return nil, []Problem{problem}
+		}
+	}
+
+	// JSON is parsed as YAML too, so every node carries a line and column
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlProblems(path, err)
+	}
+	file := &parsedFile{path: path}
+	if len(doc.Content) == 0 {
+		return file, nil // Empty file
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, []Problem{{Path: path, Line: root.Line, Column: root.Column, Message: "config must be a mapping with a \"commands\" list"}}
+	}
+	file.root = root
+
+	var problems []Problem
+	checkFields(path, root, reflect.TypeOf(Config{}), &problems)
+	if err := root.Decode(&file.config); err != nil {
+		problems = append(problems, yamlProblems(path, err)...)
+	}
+	return file, problems
+}
+
+// yamlProblems converts a yaml error into problems, one per reported line
+func yamlProblems(path string, err error) []Problem {
+	var messages []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	} else {
+		messages = []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+
+	problems := make([]Problem, 0, len(messages))
+	for _, msg := range messages {
+		problem := Problem{Path: path, Message: msg}
+		if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+			problem.Line, _ = strconv.Atoi(m[1])
+			problem.Message = m[2]
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column
+func offsetPosition(data []byte, offset int64) (line, column int) {
+	line, column = 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+// checkFields reports mapping keys that do not correspond to a field of t
+func checkFields(path string, node *yaml.Node, t reflect.Type, problems *[]Problem) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			fields[name] = f.Type
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				*problems = append(*problems, Problem{Path: path, Line: key.Line, Column: key.Column, Message: fmt.Sprintf("unknown field %q", key.Value)})
+				continue
+			}
+			checkFields(path, value, fieldType, problems)
+		}
+
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			checkFields(path, item, t.Elem(), problems)
+		}
+	}
+}
+
+// mappingValue returns the value node of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceItem returns the i-th item of a sequence node, or nil
+func sequenceItem(node *yaml.Node, i int) *yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+		return nil
+	}
+	return node.Content[i]
+}
+
+// problem creates a problem positioned at the given field of node (or node itself)
+func (f *parsedFile) problem(node *yaml.Node, field, format string, args ...interface{}) Problem {
+	if value := mappingValue(node, field); value != nil {
+		node = value
+	}
+	p := Problem{Path: f.path, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		p.Line, p.Column = node.Line, node.Column
+	}
+	return p
+}
+
+// check runs the semantic checks on a decoded file
+func (f *parsedFile) check(knownCategories map[string]bool) []Problem {
+	if f.root == nil {
+		return nil
+	}
+	var problems []Problem
+
+	placeholdersNode := mappingValue(f.root, "placeholders")
+	for i, p := range f.config.Placeholders {
+		problems = append(problems, f.checkPlaceholder(sequenceItem(placeholdersNode, i), p, "")...)
+	}
+
+	commandsNode := mappingValue(f.root, "commands")
+	firstLine := make(map[string]int)
+	for i, cmd := range f.config.Commands {
+		node := sequenceItem(commandsNode, i)
+
+		if strings.TrimSpace(cmd.Template) == "" {
+			problems = append(problems, f.problem(node, "template", "command has an empty template"))
+		} else if line, ok := firstLine[cmd.Template]; ok {
+			problems = append(problems, f.problem(node, "template", "duplicate template %q (first defined on line %d)", cmd.Template, line))
+		} else if node != nil {
+			firstLine[cmd.Template] = node.Line
+		}
+
+		if cmd.Category != "" && !knownCategories[strings.ToLower(cmd.Category)] {
+			problems = append(problems, f.problem(node, "category", "unknown category %q (declare it under \"categories\")", cmd.Category))
+		}
+		if !IsValidDangerLevel(cmd.Danger) {
+			problems = append(problems, f.problem(node, "danger", "unknown danger level %q (use %s)", cmd.Danger, strings.Join(DangerLevels, ", ")))
+		}
+		if !isValidDuration(cmd.Timeout) {
+			problems = append(problems, f.problem(node, "timeout", "invalid timeout %q (use a duration such as 30s or 10m)", cmd.Timeout))
+		}
+		if !executor.IsValidShell(cmd.Shell) {
+			problems = append(problems, f.problem(node, "shell", "invalid shell %q (use %s or %s, by name or by absolute path)", cmd.Shell, strings.Join(executor.Shells, ", "), executor.NoShell))
+		}
+
+		placeholderNodes := mappingValue(node, "placeholders")
+		for j, p := range cmd.Placeholders {
+			problems = append(problems, f.checkPlaceholder(sequenceItem(placeholderNodes, j), p, cmd.Template)...)
+		}
+	}
+
+	runbooksNode := mappingValue(f.root, "runbooks")
+	seenRunbooks := make(map[string]bool)
+	for i, rb := range f.config.Runbooks {
+		problems = append(problems, f.checkRunbook(sequenceItem(runbooksNode, i), rb, knownCategories, seenRunbooks)...)
+	}
+
+	rulesNode := mappingValue(f.root, "danger_rules")
+	for i, rule := range f.config.DangerRules {
+		node := sequenceItem(rulesNode, i)
+		if rule.ID == "" && rule.Pattern == "" {
+			problems = append(problems, f.problem(node, "id", "danger rule needs an id or a pattern"))
+		}
+		if rule.Pattern != "" {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				problems = append(problems, f.problem(node, "pattern", "invalid pattern: %v", err))
+			}
+		}
+		if !IsValidDangerLevel(rule.Level) {
+			problems = append(problems, f.problem(node, "level", "unknown danger level %q (use %s)", rule.Level, strings.Join(DangerLevels, ", ")))
+		}
+	}
+
+	if h := f.config.History; h != nil {
+		historyNode := mappingValue(f.root, "history")
+		if h.MaxEntries < 0 {
+			problems = append(problems, f.problem(historyNode, "max_entries", "max_entries must not be negative"))
+		}
+		if !history.IsValidDedupe(h.Dedupe) {
+			problems = append(problems, f.problem(historyNode, "dedupe", "unknown dedupe policy %q (use %s)", h.Dedupe, strings.Join(history.DedupePolicies, ", ")))
+		}
+	}
+
+	if fc := f.config.Frecency; fc != nil && !frecency.IsValidMode(fc.Scope) {
+		problems = append(problems, f.problem(mappingValue(f.root, "frecency"), "scope", "unknown frecency scope %q (use %s)", fc.Scope, strings.Join(frecency.Modes, ", ")))
+	}
+
+	if e := f.config.Execution; e != nil {
+		executionNode := mappingValue(f.root, "execution")
+		for _, field := range []struct{ key, value string }{
+			{"timeout", e.Timeout},
+			{"interrupt_grace", e.InterruptGrace},
+			{"terminate_grace", e.TerminateGrace},
+		} {
+			if !isValidDuration(field.value) {
+				problems = append(problems, f.problem(executionNode, field.key, "invalid %s %q (use a duration such as 30s or 10m)", field.key, field.value))
+			}
+		}
+		if !executor.IsValidShell(e.Shell) {
+			problems = append(problems, f.problem(executionNode, "shell", "invalid shell %q (use %s or %s, by name or by absolute path)", e.Shell, strings.Join(executor.Shells, ", "), executor.NoShell))
+		}
+	}
+
+	favoritesNode := mappingValue(f.root, "favorites")
+	for i, template := range f.config.Favorites {
+		if strings.TrimSpace(template) == "" {
+			problems = append(problems, f.problem(sequenceItem(favoritesNode, i), "", "empty favorite"))
+		}
+	}
+
+	setsNode := mappingValue(f.root, "favorite_sets")
+	seenSets := make(map[string]bool)
+	for i, set := range f.config.FavoriteSets {
+		node := sequenceItem(setsNode, i)
+		name := strings.ToLower(strings.TrimSpace(set.Name))
+		switch {
+		case name == "":
+			problems = append(problems, f.problem(node, "name", "favorite set has no name"))
+		case seenSets[name] || name == strings.ToLower(DefaultFavoriteSet):
+			problems = append(problems, f.problem(node, "name", "duplicate favorite set %q", set.Name))
+		}
+		seenSets[name] = true
+
+		if len(set.Commands) == 0 {
+			problems = append(problems, f.problem(node, "commands", "favorite set %q has no commands", set.Name))
+		}
+		commandsNode := mappingValue(node, "commands")
+		for j, command := range set.Commands {
+			if strings.TrimSpace(command) == "" {
+				problems = append(problems, f.problem(sequenceItem(commandsNode, j), "", "empty command in favorite set %q", set.Name))
+			}
+		}
+	}
+
+	profilesNode := mappingValue(f.root, "env_profiles")
+	seenProfiles := make(map[string]bool)
+	for i, profile := range f.config.EnvProfiles {
+		node := sequenceItem(profilesNode, i)
+		name := strings.ToLower(strings.TrimSpace(profile.Name))
+		switch {
+		case name == "":
+			problems = append(problems, f.problem(node, "name", "env profile has no name"))
+		case seenProfiles[name]:
+			problems = append(problems, f.problem(node, "name", "duplicate env profile %q", profile.Name))
+		}
+		seenProfiles[name] = true
+
+		if envNode := mappingValue(node, "env"); envNode != nil {
+			for j := 0; j+1 < len(envNode.Content); j += 2 {
+				if key := envNode.Content[j]; !session.IsValidEnvName(key.Value) {
+					problems = append(problems, f.problem(key, "", "invalid variable name %q in env profile %q", key.Value, profile.Name))
+				}
+			}
+		}
+		profile.Source = f.path
+		filesNode := mappingValue(node, "env_files")
+		for j, path := range profile.EnvFilePaths() {
+			if _, err := os.Stat(path); err != nil {
+				problems = append(problems, f.problem(sequenceItem(filesNode, j), "", "env file %q of profile %q not found", profile.EnvFiles[j], profile.Name))
+			}
+		}
+	}
+
+	targetsNode := mappingValue(f.root, "targets")
+	seenTargets := make(map[string]bool)
+	for i, target := range f.config.Targets {
+		node := sequenceItem(targetsNode, i)
+		name := strings.ToLower(strings.TrimSpace(target.Name))
+		switch {
+		case name == "":
+			problems = append(problems, f.problem(node, "name", "target has no name"))
+		case name == executor.TargetLocal:
+			problems = append(problems, f.problem(node, "name", "target name %q is reserved for this machine", target.Name))
+		case seenTargets[name]:
+			problems = append(problems, f.problem(node, "name", "duplicate target %q", target.Name))
+		}
+		seenTargets[name] = true
+
+		switch {
+		case !IsValidTargetType(target.Type):
+			problems = append(problems, f.problem(node, "type", "unknown target type %q (use %s)", target.Type, strings.Join(TargetTypes, ", ")))
+		case target.Type == executor.TargetDocker && target.Container == "":
+			problems = append(problems, f.problem(node, "", "docker target %q has no container", target.Name))
+		case target.Type == executor.TargetSSH && target.Host == "":
+			problems = append(problems, f.problem(node, "", "ssh target %q has no host", target.Name))
+		}
+		if target.Port < 0 || target.Port > 65535 {
+			problems = append(problems, f.problem(node, "port", "invalid port %d", target.Port))
+		}
+	}
+
+	disableNode := mappingValue(f.root, "disable")
+	for i, key := range f.config.Disable {
+		if strings.TrimSpace(key) == "" {
+			problems = append(problems, f.problem(sequenceItem(disableNode, i), "", "empty disable entry"))
+		}
+	}
+
+	return problems
+}
+
+// isValidDuration reports whether value is empty or a non-negative duration
+func isValidDuration(value string) bool {
+	if value == "" {
+		return true
+	}
+	d, err := time.ParseDuration(value)
+	return err == nil && d >= 0
+}
+
+// checkPlaceholder validates a placeholder declaration. If template is not
+// empty, the placeholder must also appear in it.
+func (f *parsedFile) checkPlaceholder(node *yaml.Node, p Placeholder, template string) []Problem {
+	var problems []Problem
+
+	if !placeholderNamePattern.MatchString(p.Name) {
+		problems = append(problems, f.problem(node, "name", "placeholder name %q must be UPPERCASE letters, digits and underscores", p.Name))
+	} else if template != "" && tokenIndex(template, p.Name) < 0 {
+		problems = append(problems, f.problem(node, "name", "placeholder %s does not appear in the template", p.Name))
+	}
+
+	if !IsValidType(p.Type) {
+		problems = append(problems, f.problem(node, "type", "unknown placeholder type %q (use %s)", p.Type, strings.Join(PlaceholderTypes, ", ")))
+	} else if p.Type == TypeEnum && len(p.Values) == 0 {
+		problems = append(problems, f.problem(node, "type", "enum placeholder %s needs a list of values", p.Name))
+	}
+
+	patternOK := true
+	if p.Pattern != "" {
+		if _, err := regexp.Compile("^(?:" + p.Pattern + ")$"); err != nil {
+			problems = append(problems, f.problem(node, "pattern", "invalid pattern: %v", err))
+			patternOK = false
+		}
+	}
+
+	if p.Default != "" && IsValidType(p.Type) && patternOK {
+		if err := p.Validate(p.Default); err != nil {
+			problems = append(problems, f.problem(node, "default", "default %q %v", p.Default, err))
+		}
+	}
+
+	if p.Provider != nil {
+		providerNode := mappingValue(node, "provider")
+		if strings.TrimSpace(p.Provider.Command) == "" {
+			problems = append(problems, f.problem(providerNode, "command", "provider of %s has no command", p.Name))
+		}
+		if p.Provider.TTL != "" {
+			if _, err := time.ParseDuration(p.Provider.TTL); err != nil {
+				problems = append(problems, f.problem(providerNode, "ttl", "invalid provider ttl %q", p.Provider.TTL))
+			}
+		}
+	}
+
+	return problems
+}
+
+// checkRunbook validates a runbook and its steps
+func (f *parsedFile) checkRunbook(node *yaml.Node, rb Runbook, knownCategories, seen map[string]bool) []Problem {
+	var problems []Problem
+
+	if rb.ID == "" {
+		problems = append(problems, f.problem(node, "id", "runbook has no id"))
+	} else if seen[rb.ID] {
+		problems = append(problems, f.problem(node, "id", "duplicate runbook id %q", rb.ID))
+	}
+	seen[rb.ID] = true
+
+	if rb.Name == "" {
+		problems = append(problems, f.problem(node, "name", "runbook has no name"))
+	}
+	if rb.Category != "" && !knownCategories[strings.ToLower(rb.Category)] {
+		problems = append(problems, f.problem(node, "category", "unknown category %q (declare it under \"categories\")", rb.Category))
+	}
+	if len(rb.Steps) == 0 {
+		problems = append(problems, f.problem(node, "steps", "runbook has no steps"))
+	}
+
+	stepsNode := mappingValue(node, "steps")
+	for i, step := range rb.Steps {
+		stepNode := sequenceItem(stepsNode, i)
+		if strings.TrimSpace(step.Command) == "" {
+			problems = append(problems, f.problem(stepNode, "command", "step %d has an empty command", i+1))
+		}
+		captureNodes := mappingValue(stepNode, "capture")
+		for j, c := range step.Capture {
+			captureNode := sequenceItem(captureNodes, j)
+			if !placeholderNamePattern.MatchString(c.Var) {
+				problems = append(problems, f.problem(captureNode, "var", "capture variable %q must be UPPERCASE letters, digits and underscores", c.Var))
+			}
+			switch {
+			case (c.Regex == "") == (c.JSONPath == ""):
+				problems = append(problems, f.problem(captureNode, "var", "capture %s needs exactly one of regex or jsonpath", c.Var))
+			case c.Regex != "":
+				if _, err := regexp.Compile(c.Regex); err != nil {
+					problems = append(problems, f.problem(captureNode, "regex", "invalid regex: %v", err))
+				}
+			default:
+				if err := jsonpath.Validate(c.JSONPath); err != nil {
+					problems = append(problems, f.problem(captureNode, "jsonpath", "invalid jsonpath: %v", err))
+				}
+			}
+		}
+	}
+
+	placeholderNodes := mappingValue(node, "placeholders")
+	template := rb.asCommand().Template
+	for i, p := range rb.Placeholders {
+		problems = append(problems, f.checkPlaceholder(sequenceItem(placeholderNodes, i), p, template)...)
+	}
+
+	return problems
+}
@@ -1,27 +1,144 @@
 package executor
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os/exec"
-	"runtime"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/duladissa/architerm/internal/session"
 )
 
 // Result represents the result of a command execution
 type Result struct {
-	Command  string
-	Output   string
-	Error    string
-	ExitCode int
-	Duration time.Duration
+	Command   string
+	Output    string // Stdout and stderr in the order they were written
+	Error     string
+	ExitCode  int
+	Duration  time.Duration
+	StartTime time.Time
+	Dir       string // Working directory the command ran in
+	Target    string // Name of the target the command ran on; empty means locally
+
+	// Interactive is set when the command ran on the real terminal, so its
+	// output was not captured
+	Interactive bool
+
+	// Timeout is the time limit the command ran under; zero means none
+	Timeout time.Duration
+
+	// TimedOut is set when the command was stopped for running past Timeout
+	TimedOut bool
+
+	// Cancelled is set when the command was stopped on request
+	Cancelled bool
+
+	// Chunks is the output as it was read, tagged by stream. Interactive
+	// results have none.
+	Chunks []Chunk
+
+	// Truncated is set when older output was dropped to stay within MaxOutput
+	Truncated bool
+}
+
+// MaxOutput is how much output a result keeps; once a command writes more,
+// its oldest output is dropped
+const MaxOutput = 1 << 20
+
+// TrimChunks keeps output within MaxOutput. Once size, the bytes of text in
+// chunks, exceeds it, the oldest chunks after the first keep ones are dropped
+// until the newest half remains; the newest chunk is always kept. It returns
+// the chunks, their size and whether any were dropped.
+func TrimChunks(chunks []Chunk, size, keep int) ([]Chunk, int, bool) {
+	if size <= MaxOutput || len(chunks) <= keep+1 {
+		return chunks, size, false
+	}
+	drop := keep
+	for size > MaxOutput/2 && drop < len(chunks)-1 {
+		size -= len(chunks[drop].Text)
+		drop++
+	}
+	trimmed := make([]Chunk, 0, keep+len(chunks)-drop)
+	trimmed = append(trimmed, chunks[:keep]...)
+	trimmed = append(trimmed, chunks[drop:]...)
+	return trimmed, size, true
+}
+
+// Stdout returns what the command wrote to stdout. Results without chunks,
+// such as those of interactive commands, count all output as stdout.
+func (r *Result) Stdout() string {
+	if r.Chunks == nil {
+		return r.Output
+	}
+	return JoinChunks(r.Chunks, false, true)
+}
+
+// Stderr returns what the command wrote to stderr
+func (r *Result) Stderr() string {
+	return JoinChunks(r.Chunks, true, false)
+}
+
+// JoinChunks concatenates the text of the chunks of the selected streams
+func JoinChunks(chunks []Chunk, stderr, stdout bool) string {
+	var sb strings.Builder
+	for _, chunk := range chunks {
+		if (chunk.Stderr && stderr) || (!chunk.Stderr && stdout) {
+			sb.WriteString(chunk.Text)
+		}
+	}
+	return sb.String()
+}
+
+// Default grace periods between the signals sent to stop a command
+const (
+	DefaultInterruptGrace = 2 * time.Second
+	DefaultTerminateGrace = 3 * time.Second
+)
+
+// Options controls where a command runs, how long it may run and how it is
+// stopped. A stopped command's process group gets SIGINT, then SIGTERM after
+// InterruptGrace, then SIGKILL after TerminateGrace.
+type Options struct {
+	Dir    string   // Working directory; empty means archiTerm's
+	Env    []string // Environment as NAME=VALUE; nil means archiTerm's
+	Shell  Shell    // Program the command runs in
+	Target Target   // Where the command runs; nil means locally
+
+	// SessionEnv holds the variables set in archiTerm's session, passed on
+	// to remote targets, which do not get Env
+	SessionEnv []string
+
+	Timeout        time.Duration // Zero means no limit
+	InterruptGrace time.Duration // Zero means DefaultInterruptGrace
+	TerminateGrace time.Duration // Zero means DefaultTerminateGrace
+}
+
+// graces returns the grace periods after SIGINT and after SIGTERM
+func (o Options) graces() []time.Duration {
+	interrupt, terminate := o.InterruptGrace, o.TerminateGrace
+	if interrupt <= 0 {
+		interrupt = DefaultInterruptGrace
+	}
+	if terminate <= 0 {
+		terminate = DefaultTerminateGrace
+	}
+	return []time.Duration{interrupt, terminate}
+}
+
+// Chunk is a piece of output read from a running command
+type Chunk struct {
+	Text   string
+	Stderr bool
+	Time   time.Time // When it was read
 }
 
 // Executor handles command execution
 type Executor struct {
+	// Options apply to the commands run by Execute and ExecuteStream
+	Options Options
+
 	mu         sync.Mutex
 	cancelFunc context.CancelFunc
 	isRunning  bool
@@ -34,6 +151,12 @@ func NewExecutor() *Executor {
 
 // Execute runs a command and returns the result
 func (e *Executor) Execute(command string) *Result {
+	return e.ExecuteStream(command, nil)
+}
+
+// ExecuteStream runs a command like Execute, passing output to onChunk as the
+// command writes it. onChunk is not called concurrently; it may be nil.
+func (e *Executor) ExecuteStream(command string, onChunk func(Chunk)) *Result {
 	e.mu.Lock()
 	ctx, cancel := context.WithCancel(context.Background())
 	e.cancelFunc = cancel
@@ -47,22 +170,37 @@ func (e *Executor) Execute(command string) *Result {
 		e.mu.Unlock()
 	}()
 
+	return ExecuteContext(ctx, command, e.Options, onChunk)
+}
+
+// ExecuteContext runs a command like ExecuteStream until it exits, runs past
+// opts.Timeout or ctx is cancelled. Unlike the Executor methods it can run
+// several commands at once.
+func ExecuteContext(ctx context.Context, command string, opts Options, onChunk func(Chunk)) *Result {
 	startTime := time.Now()
 	result := &Result{
-		Command: command,
+		Command:   command,
+		StartTime: startTime,
+		Dir:       opts.Dir,
+		Target:    opts.TargetName(),
+		Timeout:   opts.Timeout,
 	}
 
-	// Determine shell based on OS
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
-	} else {
-		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	runCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
 	}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	cmd := newCommand(runCtx, command, opts, false)
+	stopGracefully(cmd, opts)
+	stopRemotely(cmd, opts)
+
+	rec := &recorder{onChunk: onChunk}
+	cmd.Stdout = &streamWriter{rec: rec}
+	// Interactive shells warn about job control first, having no terminal
+	cmd.Stderr = &streamWriter{rec: rec, stderr: true, skipNotices: opts.Shell.Interactive}
 
 	err := cmd.Run()
 	result.Duration = time.Since(startTime)
@@ -71,26 +209,112 @@ func (e *Executor) Execute(command string) *Result {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
 		} else {
+			// The command could not be run at all; report why as its stderr
 			result.ExitCode = 1
+			rec.add(Chunk{Text: err.Error() + "\n", Stderr: true, Time: time.Now()})
 		}
-		result.Error = stderr.String()
-		if result.Error == "" {
-			result.Error = err.Error()
-		}
-	} else {
-		result.ExitCode = 0
 	}
 
-	result.Output = stdout.String()
+	result.Chunks = rec.chunks
+	result.Truncated = rec.truncated
+	result.Output = JoinChunks(rec.chunks, true, true)
+	result.Error = result.Stderr()
+	if result.Error == "" && err != nil {
+		result.Error = err.Error()
+	}
 
-	// If stdout is empty but we have stderr output, include it
-	if result.Output == "" && result.Error != "" {
-		result.Output = result.Error
+	if runCtx.Err() != nil {
+		if ctx.Err() != nil {
+			result.Cancelled = true
+		} else {
+			result.TimedOut = true
+		}
 	}
 
 	return result
 }
 
+// stopGracefully runs cmd in its own process group and, when its context is
+// done, stops the whole group: children such as port-forwards must not
+// outlive a cancelled command
+func stopGracefully(cmd *exec.Cmd, opts Options) {
+	setProcessGroup(cmd)
+	graces := opts.graces()
+	cmd.Cancel = func() error {
+		go stopGroup(cmd.Process.Pid, graces)
+		return nil
+	}
+	// Wait for the escalation to finish before closing the output of
+	// children that left the group
+	cmd.WaitDelay = graces[0] + graces[1] + time.Second
+}
+
+// stopGroup signals the process group led by pid to stop, escalating to the
+// next signal after each grace period while any of its processes remain
+func stopGroup(pid int, graces []time.Duration) {
+	for stage := 0; signalGroup(pid, stage) && stage < len(graces); stage++ {
+		time.Sleep(graces[stage])
+	}
+}
+
+// recorder collects the chunks written to stdout and stderr in the order
+// they arrive, passing each on to onChunk (if set) one at a time. Past
+// MaxOutput it keeps only the newest half.
+type recorder struct {
+	mu        sync.Mutex
+	chunks    []Chunk
+	size      int // Bytes of output in chunks
+	truncated bool
+	onChunk   func(Chunk)
+}
+
+// add records a chunk
+func (r *recorder) add(chunk Chunk) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var dropped bool
+	r.chunks, r.size, dropped = TrimChunks(append(r.chunks, chunk), r.size+len(chunk.Text), 0)
+	r.truncated = r.truncated || dropped
+	if r.onChunk != nil {
+		r.onChunk(chunk)
+	}
+}
+
+// streamWriter records each write to one stream as a Chunk
+type streamWriter struct {
+	rec    *recorder
+	stderr bool
+
+	// skipNotices drops job control notices until other output arrives
+	skipNotices bool
+}
+
+// Write implements io.Writer
+func (w *streamWriter) Write(p []byte) (int, error) {
+	text := string(p)
+	if w.skipNotices {
+		text = w.dropNotices(text)
+	}
+	if text != "" {
+		w.rec.add(Chunk{Text: text, Stderr: w.stderr, Time: time.Now()})
+	}
+	return len(p), nil
+}
+
+// dropNotices removes the job control notices at the start of text, and
+// stops skipping them at the first other line
+func (w *streamWriter) dropNotices(text string) string {
+	for text != "" {
+		line, rest, found := strings.Cut(text, "\n")
+		if !found || !isJobControlNotice(line) {
+			w.skipNotices = false
+			return text
+		}
+		text = rest
+	}
+	return text
+}
+
 // ExecuteAsync runs a command asynchronously and returns results via channel
 func (e *Executor) ExecuteAsync(command string) <-chan *Result {
 	resultChan := make(chan *Result, 1)
@@ -102,7 +326,7 @@ func (e *Executor) ExecuteAsync(command string) <-chan *Result {
 	return resultChan
 }
 
-// Cancel cancels the currently running command
+// Cancel stops the currently running command and the processes it started
 func (e *Executor) Cancel() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -288,16 +512,38 @@ func extractCommandName(command string) string {
 	return parts[0]
 }
 
-// FormatResult formats the result for display
-func FormatResult(r *Result) string {
+// FormatHeader formats the separator, working directory and prompt line
+// shown above a command's output
+func FormatHeader(command, dir, target string) string {
 	var sb strings.Builder
 
 	// Top separator for visual distinction between commands
 	sb.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	
+	if target != "" {
+		sb.WriteString(fmt.Sprintf("🖥  %s\n", target))
+	} else if dir != "" {
+		sb.WriteString(fmt.Sprintf("📁 %s\n", session.Abbreviate(dir)))
+	}
+
 	// Command line with prompt
-	sb.WriteString(fmt.Sprintf("$ %s\n", r.Command))
+	sb.WriteString(fmt.Sprintf("$ %s\n", command))
 	sb.WriteString("────────────────────────────────────────\n")
+	return sb.String()
+}
+
+// FormatResult formats the result for display
+func FormatResult(r *Result) string {
+	return JoinChunks(FormatResultChunks(r), true, true)
+}
+
+// FormatResultChunks formats the result like FormatResult, keeping the
+// command's output in its chunks so stderr can be told apart. The text added
+// around it counts as stdout.
+func FormatResultChunks(r *Result) []Chunk {
+	var sb strings.Builder
+	var chunks []Chunk
+
+	sb.WriteString(FormatHeader(r.Command, r.Dir, r.Target))
 
 	// Check if command was not found
 	if r.ExitCode != 0 && isCommandNotFound(r.Output) {
@@ -328,19 +574,35 @@ func FormatResult(r *Result) string {
 		sb.WriteString("   • Check PATH: echo $PATH\n")
 		sb.WriteString("   • Reload shell: source ~/.bashrc (or ~/.zshrc)\n")
 		sb.WriteString("\n")
+	} else if len(r.Chunks) > 0 {
+		if r.Truncated {
+			sb.WriteString(fmt.Sprintf("✂ [Earlier output dropped; showing the last %d KiB]\n", len(r.Output)/1024))
+		}
+		chunks = append(chunks, Chunk{Text: sb.String()})
+		chunks = append(chunks, r.Chunks...)
+		sb.Reset()
+		if !strings.HasSuffix(r.Output, "\n") {
+			sb.WriteString("\n")
+		}
 	} else if r.Output != "" {
 		sb.WriteString(r.Output)
 		if !strings.HasSuffix(r.Output, "\n") {
 			sb.WriteString("\n")
 		}
+	} else if r.Interactive {
+		sb.WriteString("🖥️  Ran interactively in the terminal; output was not captured\n")
 	}
 
 	// Status line with exit code and duration
-	if r.ExitCode != 0 {
+	if r.TimedOut {
+		sb.WriteString(fmt.Sprintf("⏱ [Timed out after %s] [Duration: %s]\n", r.Timeout, r.Duration.Round(time.Millisecond)))
+	} else if r.Cancelled {
+		sb.WriteString(fmt.Sprintf("⊘ [Cancelled] [Duration: %s]\n", r.Duration.Round(time.Millisecond)))
+	} else if r.ExitCode != 0 {
 		sb.WriteString(fmt.Sprintf("✗ [Exit code: %d] [Duration: %s]\n", r.ExitCode, r.Duration.Round(time.Millisecond)))
 	} else {
 		sb.WriteString(fmt.Sprintf("✓ [Exit code: 0] [Duration: %s]\n", r.Duration.Round(time.Millisecond)))
 	}
 
-	return sb.String()
+	return append(chunks, Chunk{Text: sb.String()})
 }
@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrimChunks(t *testing.T) {
+	quarter := strings.Repeat("x", MaxOutput/4)
+	big := strings.Repeat("x", MaxOutput+1)
+	chunk := func(text string) Chunk { return Chunk{Text: text} }
+	tests := []struct {
+		name      string
+		chunks    []Chunk
+		keep      int
+		wantLen   int
+		wantFirst string
+		dropped   bool
+	}{
+		{"within the limit", []Chunk{chunk("a"), chunk("b")}, 0, 2, "a", false},
+		{"keeps the newest half", []Chunk{chunk("a"), chunk(quarter), chunk(quarter), chunk(quarter), chunk(quarter)}, 0, 2, quarter, true},
+		{"keeps the header", []Chunk{chunk("header"), chunk(quarter), chunk(quarter), chunk(quarter), chunk(quarter), chunk("b")}, 1, 3, "header", true},
+		{"keeps the newest chunk", []Chunk{chunk("a"), chunk(big)}, 0, 1, big, true},
+		{"single chunk", []Chunk{chunk(big)}, 0, 1, big, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := 0
+			for _, c := range tt.chunks {
+				size += len(c.Text)
+			}
+			got, gotSize, dropped := TrimChunks(tt.chunks, size, tt.keep)
+			if len(got) != tt.wantLen || got[0].Text != tt.wantFirst || dropped != tt.dropped {
+				t.Fatalf("TrimChunks() = %d chunks starting %.10q, dropped %v; want %d starting %.10q, dropped %v",
+					len(got), got[0].Text, dropped, tt.wantLen, tt.wantFirst, tt.dropped)
+			}
+			want := 0
+			for _, c := range got {
+				want += len(c.Text)
+			}
+			if gotSize != want {
+				t.Errorf("TrimChunks() size = %d, want %d", gotSize, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// InteractiveCommand returns an exec.Cmd running command in the configured shell
+// and target on the real terminal. Its stdin, stdout and stderr are left for
+// the caller (such as tea.ExecProcess) to connect. Timeouts do not apply.
+func InteractiveCommand(command string, opts Options) *exec.Cmd {
+	return newCommand(context.Background(), command, opts, true)
+}
+
+// InteractiveResult builds the result of an interactive command run with
+// opts that started at start and finished with err
+func InteractiveResult(command string, opts Options, start time.Time, err error) *Result {
+	result := &Result{
+		Command:     command,
+		StartTime:   start,
+		Dir:         opts.Dir,
+		Target:      opts.TargetName(),
+		Duration:    time.Since(start),
+		Interactive: true,
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+		} else {
+			result.ExitCode = 1
+			result.Error = err.Error()
+			result.Output = result.Error
+		}
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package executor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// stopSignals are sent to a command's process group in turn to stop it
+var stopSignals = []syscall.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL}
+
+// setProcessGroup starts cmd in a new session, and so a new process group led
+// by the shell. The session has no controlling terminal: a command opening
+// /dev/tty (such as an interactive shell) would otherwise be stopped for
+// using it from a background process group.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// signalGroup sends the signal for the given stop stage to the process group
+// led by pid. It reports false when no process of the group is left.
+func signalGroup(pid int, stage int) bool {
+	if err := syscall.Kill(-pid, 0); err != nil {
+		return false
+	}
+	return syscall.Kill(-pid, stopSignals[stage]) == nil
+}
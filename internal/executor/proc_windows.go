@@ -0,0 +1,22 @@
+//go:build windows
+
+package executor
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in a new process group
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// signalGroup stops the process tree of pid. Windows cannot interrupt
+// another console process group, so the tree is killed at the first stage
+// and there is nothing left to escalate.
+func signalGroup(pid int, stage int) bool {
+	exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid)).Run()
+	return false
+}
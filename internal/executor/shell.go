@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/duladissa/architerm/internal/session"
+)
+
+// NoShell is the shell name for running commands directly, without a shell
+const NoShell = "none"
+
+// Shells are the shells commands can run in, besides NoShell. A path to one
+// of them (such as /opt/homebrew/bin/bash) works too.
+var Shells = []string{"sh", "bash", "zsh", "fish", "pwsh", "powershell", "cmd"}
+
+// Shell is the program commands run in
+type Shell struct {
+	// Program is a shell from Shells or a path to one, or NoShell. Empty
+	// means sh, or cmd on Windows.
+	Program string
+
+	Login       bool // Start a login shell, which reads profile files
+	Interactive bool // Start an interactive shell, which reads rc files and aliases
+}
+
+// IsValidShell reports whether program is empty or a shell commands can run
+// in, given by name or by absolute path
+func IsValidShell(program string) bool {
+	return program == "" || program == NoShell || (shellName(program) != "" && !isRelativePath(program))
+}
+
+// isRelativePath reports whether program is a path that is not absolute.
+// Such a path would run whatever the working directory, perhaps a cloned
+// repository, has there. Bare names are looked up in PATH instead, and paths
+// may start with ~/. POSIX paths count as absolute on Windows too, as the
+// shell may run on a remote target.
+func isRelativePath(program string) bool {
+	if !strings.ContainsAny(program, `/\`) {
+		return false
+	}
+	program = session.ExpandHome(program)
+	return !filepath.IsAbs(program) && !strings.HasPrefix(program, "/")
+}
+
+// shellName returns the name of the shell program runs, or "" if unknown
+func shellName(program string) string {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(program)), ".exe")
+	switch name {
+	case "dash", "ksh", "mksh":
+		return "sh"
+	}
+	for _, shell := range Shells {
+		if name == shell {
+			return name
+		}
+	}
+	return ""
+}
+
+// shellArgv returns the program and arguments that run command in shell.
+// The default shell is sh, or cmd for commands run locally on Windows.
+func shellArgv(command string, shell Shell, remote bool) ([]string, error) {
+	program := shell.Program
+	if program == "" {
+		program = "sh"
+		if runtime.GOOS == "windows" && !remote {
+			program = "cmd"
+		}
+	}
+
+	if isRelativePath(program) {
+		return nil, fmt.Errorf("shell %q is a relative path; use a name or an absolute path", program)
+	}
+
+	argv := []string{program}
+	switch shellName(program) {
+	case "cmd":
+		argv = append(argv, "/C", command)
+	case "pwsh", "powershell":
+		// -Login is only understood by pwsh, and must come first
+		if shell.Login && shellName(program) == "pwsh" {
+			argv = append(argv, "-Login")
+		}
+		argv = append(argv, "-NoLogo")
+		if !shell.Interactive {
+			argv = append(argv, "-NoProfile", "-NonInteractive")
+		}
+		argv = append(argv, "-Command", command)
+	case "":
+		if program == NoShell {
+			return directArgv(command)
+		}
+		return nil, fmt.Errorf("unknown shell %q", program)
+	default:
+		if shell.Login {
+			argv = append(argv, "-l")
+		}
+		if shell.Interactive {
+			argv = append(argv, "-i")
+		}
+		argv = append(argv, "-c", command)
+	}
+	return argv, nil
+}
+
+// jobControlNotice matches the warnings an interactive shell started without
+// a controlling terminal prints before running its command (bash's "cannot
+// set terminal process group" and "no job control in this shell", dash's
+// "can't access tty; job control turned off", zsh's "can't set tty pgrp")
+var jobControlNotice = regexp.MustCompile(`^\S+: (?:\d+: )?(?:cannot set terminal process group|no job control in this shell|can't access tty; job control turned off|can't set tty pgrp)`)
+
+// isJobControlNotice reports whether line is such a warning
+func isJobControlNotice(line string) bool {
+	return jobControlNotice.MatchString(strings.TrimSuffix(line, "\r"))
+}
+
+// directArgv splits command into a program and its arguments for running it
+// without a shell, removing quotes. Pipes, redirections and variables are
+// not supported.
+func directArgv(command string) ([]string, error) {
+	words, err := session.SplitWords(command)
+	if err == nil && len(words) == 0 {
+		err = fmt.Errorf("empty command")
+	}
+	return words, err
+}
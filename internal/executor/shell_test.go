@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestShellArgv(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		shell   Shell
+		remote  bool
+		want    []string
+	}{
+		{"default remote", "echo hi", Shell{}, true, []string{"sh", "-c", "echo hi"}},
+		{"bash", "ls | wc -l", Shell{Program: "bash"}, false, []string{"bash", "-c", "ls | wc -l"}},
+		{"login interactive", "ll", Shell{Program: "/opt/homebrew/bin/zsh", Login: true, Interactive: true}, false,
+			[]string{"/opt/homebrew/bin/zsh", "-l", "-i", "-c", "ll"}},
+		{"dash counts as sh", "true", Shell{Program: "dash", Login: true}, false, []string{"dash", "-l", "-c", "true"}},
+		{"cmd", "dir", Shell{Program: "cmd.exe"}, false, []string{"cmd.exe", "/C", "dir"}},
+		{"pwsh", "Get-Date", Shell{Program: "pwsh", Login: true}, false,
+			[]string{"pwsh", "-Login", "-NoLogo", "-NoProfile", "-NonInteractive", "-Command", "Get-Date"}},
+		{"powershell interactive", "Get-Date", Shell{Program: "powershell", Login: true, Interactive: true}, false,
+			[]string{"powershell", "-NoLogo", "-Command", "Get-Date"}},
+		{"no shell", `grep -r "a b" .`, Shell{Program: NoShell}, false, []string{"grep", "-r", "a b", "."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shellArgv(tt.command, tt.shell, tt.remote)
+			if err != nil {
+				t.Fatalf("shellArgv() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shellArgv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellArgvDefaultLocal(t *testing.T) {
+	got, err := shellArgv("echo hi", Shell{}, false)
+	if err != nil {
+		t.Fatalf("shellArgv() error = %v", err)
+	}
+	want := []string{"sh", "-c", "echo hi"}
+	if runtime.GOOS == "windows" {
+		want = []string{"cmd", "/C", "echo hi"}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shellArgv() = %q, want %q", got, want)
+	}
+}
+
+func TestShellArgvErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		shell   Shell
+	}{
+		{"unknown shell", "echo hi", Shell{Program: "tcsh"}},
+		{"relative path", "echo hi", Shell{Program: "./bin/bash"}},
+		{"empty direct command", "  ", Shell{Program: NoShell}},
+		{"unterminated quote", `echo "hi`, Shell{Program: NoShell}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := shellArgv(tt.command, tt.shell, false); err == nil {
+				t.Errorf("shellArgv() = %q, want an error", got)
+			}
+		})
+	}
+}
+
+func TestIsValidShell(t *testing.T) {
+	tests := []struct {
+		program string
+		valid   bool
+	}{
+		{"", true},
+		{NoShell, true},
+		{"bash", true},
+		{"/usr/local/bin/fish", true},
+		{"PowerShell.exe", true},
+		{"ksh", true},
+		{"~/bin/zsh", true},
+		{`C:\Program Files\PowerShell\7\pwsh.exe`, runtime.GOOS == "windows"},
+		{"tcsh", false},
+		{"python", false},
+		{"./bin/bash", false},
+		{"bin/bash", false},
+		{"../sh", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidShell(tt.program); got != tt.valid {
+			t.Errorf("IsValidShell(%q) = %v, want %v", tt.program, got, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Target types that can be configured
+const (
+	TargetLocal  = "local"
+	TargetDocker = "docker"
+	TargetSSH    = "ssh"
+)
+
+// Target is where commands run: on this machine, inside a container or on a
+// remote host. Options without a target run commands locally.
+type Target interface {
+	// Name identifies the target in results, history and the target switcher
+	Name() string
+
+	// Command returns an exec.Cmd running argv, a shell with its arguments or
+	// a program, on the target. terminal is set when the command gets a
+	// terminal for its input and output.
+	Command(ctx context.Context, argv []string, opts Options, terminal bool) *exec.Cmd
+}
+
+// stopOnHangup is a POSIX sh script running its arguments as a command that is
+// stopped once the script's input ends. Captured commands run on docker and
+// ssh targets under it: stopping the local client alone would leave them
+// running, as the remote end does not notice. The command runs in its own
+// session when setsid is available, so a pipeline is stopped whole, with
+// SIGTERM and then SIGKILL after the given number of seconds. SIGINT is not
+// sent, since commands started in the background ignore it.
+const stopOnHangup = `exec 3<&0; ` +
+	`if command -v setsid >/dev/null 2>&1; then setsid "$@" </dev/null 3<&- & else "$@" </dev/null 3<&- & fi; ` +
+	`pid=$!; ` +
+	`{ while read -r _; do :; done; for sig in TERM KILL; do kill -s $sig -- -$pid 2>/dev/null || kill -s $sig $pid 2>/dev/null || exit; sleep %d; done; } <&3 >/dev/null 2>&1 3<&- & ` +
+	`watch=$!; exec 3<&-; wait $pid; status=$?; kill $watch 2>/dev/null; exit $status`
+
+// hangupArgv wraps argv in stopOnHangup, using the terminate grace of opts
+func hangupArgv(argv []string, opts Options) []string {
+	grace := int(math.Ceil(opts.graces()[1].Seconds()))
+	return append([]string{"sh", "-c", fmt.Sprintf(stopOnHangup, grace), "sh"}, argv...)
+}
+
+// LocalTarget runs commands on this machine
+type LocalTarget struct{}
+
+// Name implements Target
+func (LocalTarget) Name() string {
+	return TargetLocal
+}
+
+// Command implements Target
+func (LocalTarget) Command(ctx context.Context, argv []string, opts Options, terminal bool) *exec.Cmd {
+	return exec.CommandContext(ctx, argv[0], argv[1:]...)
+}
+
+// DockerTarget runs commands inside a running container with docker exec
+type DockerTarget struct {
+	name      string
+	container string
+	dir       string
+	args      []string
+}
+
+// NewDockerTarget creates a target running commands in container, in dir
+// (if set), with extra docker exec arguments
+func NewDockerTarget(name, container, dir string, args []string) *DockerTarget {
+	return &DockerTarget{name: name, container: container, dir: dir, args: args}
+}
+
+// Name implements Target
+func (t *DockerTarget) Name() string {
+	return t.name
+}
+
+// Command implements Target. The session's variables are passed with -e; the
+// working directory is the target's own. Captured commands keep their input
+// open to be stopped when it ends (see stopOnHangup); commands on a terminal
+// keep running in the container if docker exec is stopped.
+func (t *DockerTarget) Command(ctx context.Context, argv []string, opts Options, terminal bool) *exec.Cmd {
+	args := []string{"exec"}
+	if terminal {
+		args = append(args, "-it")
+	} else {
+		args = append(args, "-i")
+		argv = hangupArgv(argv, opts)
+	}
+	if t.dir != "" {
+		args = append(args, "-w", t.dir)
+	}
+	for _, kv := range opts.SessionEnv {
+		args = append(args, "-e", kv)
+	}
+	args = append(args, t.args...)
+	args = append(args, t.container)
+	return exec.CommandContext(ctx, "docker", append(args, argv...)...)
+}
+
+// SSHTarget runs commands on a remote host with the system ssh client
+type SSHTarget struct {
+	name string
+	host string
+	port int
+	dir  string
+	args []string
+}
+
+// NewSSHTarget creates a target running commands on host ([user@]host), in
+// dir (if set), with extra ssh arguments such as -i for an identity file.
+// A zero port means ssh's default.
+func NewSSHTarget(name, host string, port int, dir string, args []string) *SSHTarget {
+	return &SSHTarget{name: name, host: host, port: port, dir: dir, args: args}
+}
+
+// Name implements Target
+func (t *SSHTarget) Name() string {
+	return t.name
+}
+
+// Command implements Target. Without a terminal ssh runs in batch mode, so
+// it fails instead of prompting for a password, and the command is stopped
+// when its input ends (see stopOnHangup); on a terminal it gets SIGHUP when
+// the connection closes. The session's variables are set with env on the
+// remote host.
+func (t *SSHTarget) Command(ctx context.Context, argv []string, opts Options, terminal bool) *exec.Cmd {
+	args := []string{"-T", "-o", "BatchMode=yes"}
+	if terminal {
+		args = []string{"-t"}
+	} else {
+		argv = hangupArgv(argv, opts)
+	}
+	if t.port != 0 {
+		args = append(args, "-p", strconv.Itoa(t.port))
+	}
+	args = append(args, t.args...)
+
+	// ssh passes the command to the remote user's shell as one string
+	var remote []string
+	if t.dir != "" {
+		remote = append(remote, "cd", ShellQuote(t.dir), "&&")
+	}
+	if len(opts.SessionEnv) > 0 {
+		remote = append(remote, "env")
+		for _, kv := range opts.SessionEnv {
+			remote = append(remote, ShellQuote(kv))
+		}
+	}
+	for _, arg := range argv {
+		remote = append(remote, ShellQuote(arg))
+	}
+	args = append(args, t.host, "--", strings.Join(remote, " "))
+	return exec.CommandContext(ctx, "ssh", args...)
+}
+
+// safeWord matches words a POSIX shell reads literally
+var safeWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ShellQuote quotes s for a POSIX shell, leaving words it reads literally as they are
+func ShellQuote(s string) string {
+	if safeWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// targetOf returns the target of opts, which is local if not set
+func targetOf(opts Options) Target {
+	if opts.Target == nil {
+		return LocalTarget{}
+	}
+	return opts.Target
+}
+
+// IsLocal reports whether opts run commands on this machine
+func (o Options) IsLocal() bool {
+	_, local := targetOf(o).(LocalTarget)
+	return local
+}
+
+// TargetName returns the name of the target of opts, or "" if it is local
+func (o Options) TargetName() string {
+	if o.IsLocal() {
+		return ""
+	}
+	return o.Target.Name()
+}
+
+// newCommand returns an exec.Cmd running command in the shell and on the
+// target of opts. The local process (docker or ssh for remote targets) gets
+// the working directory and environment of opts.
+func newCommand(ctx context.Context, command string, opts Options, terminal bool) *exec.Cmd {
+	argv, err := shellArgv(command, opts.Shell, !opts.IsLocal())
+	var cmd *exec.Cmd
+	if err != nil {
+		cmd = exec.CommandContext(ctx, "sh")
+		cmd.Err = err
+	} else {
+		cmd = targetOf(opts).Command(ctx, argv, opts, terminal)
+	}
+	cmd.Dir = opts.Dir
+	cmd.Env = opts.Env
+	return cmd
+}
+
+// stopRemotely makes stopping a captured command on a remote target close its
+// input, so the command is stopped on the target (see stopOnHangup). The local
+// client is only stopped if it is still running once that has had time.
+func stopRemotely(cmd *exec.Cmd, opts Options) {
+	if opts.IsLocal() || cmd.Cancel == nil {
+		return
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return
+	}
+	stop := cmd.Cancel
+	delay := opts.graces()[1] + time.Second
+	cmd.Cancel = func() error {
+		stdin.Close()
+		time.AfterFunc(delay, func() { stop() })
+		return nil
+	}
+	cmd.WaitDelay += delay
+}
@@ -0,0 +1,121 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"ls", "ls"},
+		{"/var/log/app.log", "/var/log/app.log"},
+		{"KEY=value,other:1@host%2", "KEY=value,other:1@host%2"},
+		{"", "''"},
+		{"two words", "'two words'"},
+		{"$HOME", "'$HOME'"},
+		{"it's", `'it'\''s'`},
+		{"a;b|c", "'a;b|c'"},
+	}
+	for _, tt := range tests {
+		if got := ShellQuote(tt.in); got != tt.want {
+			t.Errorf("ShellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// hangupScript is the stopOnHangup script for a terminate grace of seconds
+func hangupScript(seconds int) string {
+	return fmt.Sprintf(stopOnHangup, seconds)
+}
+
+func TestDockerTargetCommand(t *testing.T) {
+	target := NewDockerTarget("api", "api-1", "/app", []string{"--user", "app"})
+	argv := []string{"sh", "-c", "ls -la"}
+	opts := Options{SessionEnv: []string{"A=1", "B=two words"}}
+
+	tests := []struct {
+		name     string
+		terminal bool
+		want     []string
+	}{
+		{"captured", false, []string{"docker", "exec", "-i", "-w", "/app", "-e", "A=1", "-e", "B=two words", "--user", "app", "api-1",
+			"sh", "-c", hangupScript(3), "sh", "sh", "-c", "ls -la"}},
+		{"terminal", true, []string{"docker", "exec", "-it", "-w", "/app", "-e", "A=1", "-e", "B=two words", "--user", "app", "api-1",
+			"sh", "-c", "ls -la"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := target.Command(context.Background(), argv, opts, tt.terminal)
+			if !reflect.DeepEqual(cmd.Args, tt.want) {
+				t.Errorf("Args = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerTargetCommandMinimal(t *testing.T) {
+	target := NewDockerTarget("api", "api-1", "", nil)
+	cmd := target.Command(context.Background(), []string{"date"}, Options{}, true)
+	want := []string{"docker", "exec", "-it", "api-1", "date"}
+	if !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("Args = %q, want %q", cmd.Args, want)
+	}
+}
+
+func TestSSHTargetCommand(t *testing.T) {
+	argv := []string{"sh", "-c", "tail -f /var/log/app.log | grep 'ERR'"}
+	opts := Options{SessionEnv: []string{"A=1", "B=two words"}, TerminateGrace: 1500 * time.Millisecond}
+	remoteArgv := `sh -c 'tail -f /var/log/app.log | grep '\''ERR'\'''`
+
+	tests := []struct {
+		name     string
+		target   *SSHTarget
+		terminal bool
+		want     []string
+	}{
+		{
+			"captured",
+			NewSSHTarget("web", "deploy@web-1", 2222, "/srv/app", []string{"-i", "~/.ssh/deploy"}),
+			false,
+			[]string{"ssh", "-T", "-o", "BatchMode=yes", "-p", "2222", "-i", "~/.ssh/deploy", "deploy@web-1", "--",
+				"cd /srv/app && env A=1 'B=two words' sh -c " + ShellQuote(hangupScript(2)) + " sh " + remoteArgv},
+		},
+		{
+			"terminal",
+			NewSSHTarget("web", "web-1", 0, "", nil),
+			true,
+			[]string{"ssh", "-t", "web-1", "--", "env A=1 'B=two words' " + remoteArgv},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tt.target.Command(context.Background(), argv, opts, tt.terminal)
+			if !reflect.DeepEqual(cmd.Args, tt.want) {
+				t.Errorf("Args = %q\nwant %q", cmd.Args, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCommandOnTarget(t *testing.T) {
+	opts := Options{
+		Dir:    "/tmp",
+		Env:    []string{"LOCAL=1"},
+		Shell:  Shell{Program: "bash", Login: true},
+		Target: NewSSHTarget("web", "web-1", 0, "", nil),
+	}
+	cmd := newCommand(context.Background(), "uptime", opts, true)
+	want := []string{"ssh", "-t", "web-1", "--", "bash -l -c uptime"}
+	if !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("Args = %q, want %q", cmd.Args, want)
+	}
+	if cmd.Dir != "/tmp" || !reflect.DeepEqual(cmd.Env, opts.Env) {
+		t.Errorf("local client runs in %q with %q, want %q with %q", cmd.Dir, cmd.Env, opts.Dir, opts.Env)
+	}
+}
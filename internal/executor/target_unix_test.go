@@ -0,0 +1,77 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDocker puts a docker stand-in on PATH that runs the command of
+// "docker exec [flags] CONTAINER argv..." on this machine. Like a command run
+// by the docker daemon, it is in a session of its own, so stopping the client
+// does not stop it.
+func fakeDocker(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("setsid not found")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+shift
+while [ $# -gt 0 ]; do
+	case "$1" in
+	-i|-it) shift ;;
+	-e|-w) shift 2 ;;
+	*) shift; break ;;
+	esac
+done
+exec setsid -w "$@"
+`
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestExecuteOnTarget(t *testing.T) {
+	fakeDocker(t)
+	opts := Options{Target: NewDockerTarget("api", "api-1", "", nil)}
+
+	result := ExecuteContext(context.Background(), "echo out; echo err >&2; exit 3", opts, nil)
+	if result.Stdout() != "out\n" || result.Stderr() != "err\n" || result.ExitCode != 3 {
+		t.Errorf("got stdout %q, stderr %q, exit code %d", result.Stdout(), result.Stderr(), result.ExitCode)
+	}
+	if result.Target != "api" {
+		t.Errorf("Target = %q, want api", result.Target)
+	}
+}
+
+func TestCancelOnTargetStopsRemoteCommand(t *testing.T) {
+	fakeDocker(t)
+	marker := filepath.Join(t.TempDir(), "marker")
+	opts := Options{Target: NewDockerTarget("api", "api-1", "", nil), TerminateGrace: time.Second}
+
+	// The subshell is stopped only if the command's whole process group is
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	result := ExecuteContext(ctx, "(sleep 1; touch "+marker+") | cat", opts, nil)
+	if !result.TimedOut && !result.Cancelled {
+		t.Fatalf("command was not stopped: %+v", result)
+	}
+	if result.Duration > time.Second {
+		t.Errorf("stopping took %s; the remote command should stop when its input ends", result.Duration)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("remote command kept running after it was cancelled")
+	} else if !strings.Contains(err.Error(), "no such file") {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,272 @@
+package frecency
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/duladissa/architerm/internal/filelock"
+)
+
+// FileName is the name of the frecency file in the data directory
+const FileName = "frecency.json"
+
+// HalfLife is how long it takes for a use to count half as much
+const HalfLife = 7 * 24 * time.Hour
+
+// GlobalScope is the scope counting uses in every directory
+const GlobalScope = ""
+
+// scopedWeight is how much global uses count towards a directory or repo score
+const scopedWeight = 0.25
+
+// pruneBelow drops stats whose decayed score has fallen below this value
+const pruneBelow = 0.01
+
+// Scope modes for Store.Score
+const (
+	ModeGlobal    = "global"    // rank by uses anywhere
+	ModeDirectory = "directory" // rank by uses in the working directory
+	ModeRepo      = "repo"      // rank by uses in the enclosing git repository
+)
+
+// Modes lists the valid scope modes
+var Modes = []string{ModeGlobal, ModeDirectory, ModeRepo}
+
+// IsValidMode reports whether mode is a known scope mode (empty means global)
+func IsValidMode(mode string) bool {
+	if mode == "" {
+		return true
+	}
+	for _, m := range Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Stat is the usage of one template in one scope
+type Stat struct {
+	// Score is the frecency at Last; it halves every HalfLife after that
+	Score float64   `json:"score"`
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// Decayed returns the score at time now
+func (s Stat) Decayed(now time.Time) float64 {
+	age := now.Sub(s.Last)
+	if age < 0 {
+		age = 0
+	}
+	return s.Score * math.Pow(0.5, float64(age)/float64(HalfLife))
+}
+
+// TemplateStat is a template with its usage, as returned by Stats
+type TemplateStat struct {
+	Template string    `json:"template"`
+	Score    float64   `json:"score"`
+	Count    int       `json:"count"`
+	Last     time.Time `json:"last"`
+}
+
+// Store persists template usage per scope. The global scope counts every use;
+// directory and repo scopes count the uses in that place.
+type Store struct {
+	path   string
+	scopes map[string]map[string]Stat
+}
+
+// DefaultPath returns the default frecency file path in dataDir
+func DefaultPath(dataDir string) string {
+	return filepath.Join(dataDir, FileName)
+}
+
+// Open loads the frecency file at path. A missing file gives an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, scopes: make(map[string]map[string]Stat)}
+	if err := s.load(); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// Path returns the path of the frecency file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// load replaces the in-memory stats with the file's contents
+func (s *Store) load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file struct {
+		Scopes map[string]map[string]Stat `json:"scopes"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	if file.Scopes != nil {
+		s.scopes = file.Scopes
+	}
+	return nil
+}
+
+// save writes the stats to the file, dropping those that have decayed away
+func (s *Store) save(now time.Time) error {
+	for scope, stats := range s.scopes {
+		for template, stat := range stats {
+			if stat.Decayed(now) < pruneBelow {
+				delete(stats, template)
+			}
+		}
+		if len(stats) == 0 {
+			delete(s.scopes, scope)
+		}
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{"scopes": s.scopes}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return filelock.WriteFile(s.path, append(data, '\n'))
+}
+
+// update re-reads the file, applies fn and writes it back while holding the
+// lock, so uses recorded by other archiTerm instances are kept
+func (s *Store) update(now time.Time, fn func()) error {
+	unlock, err := filelock.Lock(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	fn()
+	return s.save(now)
+}
+
+// Record counts a use of template in the global scope and in scope
+func (s *Store) Record(template, scope string, now time.Time) error {
+	return s.update(now, func() {
+		s.bump(GlobalScope, template, now)
+		if scope != GlobalScope {
+			s.bump(scope, template, now)
+		}
+	})
+}
+
+// bump adds one use to the stat of template in scope
+func (s *Store) bump(scope, template string, now time.Time) {
+	stats := s.scopes[scope]
+	if stats == nil {
+		stats = make(map[string]Stat)
+		s.scopes[scope] = stats
+	}
+	stat := stats[template]
+	stats[template] = Stat{
+		Score: stat.Decayed(now) + 1,
+		Count: stat.Count + 1,
+		Last:  now,
+	}
+}
+
+// Score returns the frecency of template for scope. Outside the global scope,
+// global uses count for a quarter so commands used elsewhere still rank.
+func (s *Store) Score(template, scope string, now time.Time) float64 {
+	score := s.scopes[GlobalScope][template].Decayed(now)
+	if scope == GlobalScope {
+		return score
+	}
+	return s.scopes[scope][template].Decayed(now) + scopedWeight*score
+}
+
+// Stats returns the templates used in scope, highest score first
+func (s *Store) Stats(scope string, now time.Time) []TemplateStat {
+	var stats []TemplateStat
+	for template, stat := range s.scopes[scope] {
+		stats = append(stats, TemplateStat{
+			Template: template,
+			Score:    stat.Decayed(now),
+			Count:    stat.Count,
+			Last:     stat.Last,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Score != stats[j].Score {
+			return stats[i].Score > stats[j].Score
+		}
+		return stats[i].Template < stats[j].Template
+	})
+	return stats
+}
+
+// Scopes returns the directory and repo scopes with recorded uses, sorted
+func (s *Store) Scopes() []string {
+	var scopes []string
+	for scope := range s.scopes {
+		if scope != GlobalScope {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// Reset forgets the uses recorded in scope
+func (s *Store) Reset(scope string) error {
+	now := time.Now()
+	return s.update(now, func() {
+		delete(s.scopes, scope)
+	})
+}
+
+// ResetAll forgets all recorded uses
+func (s *Store) ResetAll() error {
+	now := time.Now()
+	return s.update(now, func() {
+		s.scopes = make(map[string]map[string]Stat)
+	})
+}
+
+// ScopeFor returns the scope key for dir under mode: the directory itself,
+// the root of its git repository (or dir outside a repository), or the
+// global scope
+func ScopeFor(dir, mode string) string {
+	switch mode {
+	case ModeDirectory:
+		return dir
+	case ModeRepo:
+		if root := findRepoRoot(dir); root != "" {
+			return root
+		}
+		return dir
+	}
+	return GlobalScope
+}
+
+// findRepoRoot walks up from dir to the directory containing .git, if any
+func findRepoRoot(dir string) string {
+	for dir != "" {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+package frecency
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestStatDecayed(t *testing.T) {
+	stat := Stat{Score: 4, Count: 4, Last: t0}
+	tests := []struct {
+		now  time.Time
+		want float64
+	}{
+		{t0, 4},
+		{t0.Add(HalfLife), 2},
+		{t0.Add(2 * HalfLife), 1},
+		{t0.Add(HalfLife / 2), 4 / math.Sqrt2},
+		{t0.Add(-time.Hour), 4},
+	}
+	for _, tt := range tests {
+		if got := stat.Decayed(tt.now); !approx(got, tt.want) {
+			t.Errorf("Decayed(%s) = %v, want %v", tt.now.Sub(t0), got, tt.want)
+		}
+	}
+}
+
+func TestRecordDecaysEarlierUses(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, now := range []time.Time{t0, t0.Add(HalfLife)} {
+		if err := store.Record("kubectl get pods", GlobalScope, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first use counts half by the time of the second
+	if got := store.Score("kubectl get pods", GlobalScope, t0.Add(HalfLife)); !approx(got, 1.5) {
+		t.Errorf("Score() = %v, want 1.5", got)
+	}
+	if stats := store.Stats(GlobalScope, t0.Add(HalfLife)); len(stats) != 1 || stats[0].Count != 2 {
+		t.Errorf("Stats() = %+v, want one template used twice", stats)
+	}
+}
+
+func TestScore(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := func(template, scope string, times int) {
+		for i := 0; i < times; i++ {
+			if err := store.Record(template, scope, t0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	record("make test", "/src/api", 2)
+	record("make test", "/src/web", 1)
+	record("docker compose up", GlobalScope, 4)
+
+	tests := []struct {
+		name     string
+		template string
+		scope    string
+		want     float64
+	}{
+		{"global counts every use", "make test", GlobalScope, 3},
+		{"scope adds a quarter of global uses", "make test", "/src/api", 2 + 0.25*3},
+		{"other scope", "make test", "/src/web", 1 + 0.25*3},
+		{"used elsewhere only", "docker compose up", "/src/api", 0.25 * 4},
+		{"unused", "ls", "/src/api", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := store.Score(tt.template, tt.scope, t0); !approx(got, tt.want) {
+				t.Errorf("Score(%q, %q) = %v, want %v", tt.template, tt.scope, got, tt.want)
+			}
+		})
+	}
+
+	if got := store.Scopes(); len(got) != 2 || got[0] != "/src/api" || got[1] != "/src/web" {
+		t.Errorf("Scopes() = %q, want [/src/api /src/web]", got)
+	}
+	stats := store.Stats(GlobalScope, t0)
+	if len(stats) != 2 || stats[0].Template != "docker compose up" || stats[1].Template != "make test" {
+		t.Errorf("Stats() = %+v, want docker compose up before make test", stats)
+	}
+}
+
+func TestStorePersistsAcrossInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", FileName)
+	first, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Record("git status", "/src/api", t0); err != nil {
+		t.Fatal(err)
+	}
+	// second re-reads the file before writing, keeping the first's use
+	if err := second.Record("git status", "/src/api", t0); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Score("git status", "/src/api", t0); !approx(got, 2+0.25*2) {
+		t.Errorf("Score() = %v after two instances recorded a use each, want 2.5", got)
+	}
+}
+
+func TestSavePrunesDecayedStats(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Record("old", "/src/api", t0); err != nil {
+		t.Fatal(err)
+	}
+	later := t0.Add(8 * HalfLife)
+	if err := store.Record("new", GlobalScope, later); err != nil {
+		t.Fatal(err)
+	}
+	if stats := store.Stats(GlobalScope, later); len(stats) != 1 || stats[0].Template != "new" {
+		t.Errorf("Stats() = %+v, want only the new template", stats)
+	}
+	if scopes := store.Scopes(); len(scopes) != 0 {
+		t.Errorf("Scopes() = %q, want the emptied scope dropped", scopes)
+	}
+}
+
+func TestReset(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := store.Record("make", "/src/api", now); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Reset("/src/api"); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Score("make", "/src/api", now); !approx(got, 0.25) {
+		t.Errorf("Score() = %v after resetting the scope, want only the global quarter", got)
+	}
+	if err := store.ResetAll(); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Score("make", GlobalScope, now); got != 0 {
+		t.Errorf("Score() = %v after ResetAll, want 0", got)
+	}
+}
+
+func TestScopeFor(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(repo, "cmd", "api")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+
+	tests := []struct {
+		dir  string
+		mode string
+		want string
+	}{
+		{sub, ModeGlobal, GlobalScope},
+		{sub, "", GlobalScope},
+		{sub, ModeDirectory, sub},
+		{sub, ModeRepo, repo},
+		{repo, ModeRepo, repo},
+		{outside, ModeRepo, outside},
+	}
+	for _, tt := range tests {
+		if got := ScopeFor(tt.dir, tt.mode); got != tt.want {
+			t.Errorf("ScopeFor(%q, %q) = %q, want %q", tt.dir, tt.mode, got, tt.want)
+		}
+	}
+}
@@ -1,47 +1,151 @@
 package history
 
-// History manages session command history
+import "time"
+
+// DefaultMaxEntries is the default number of commands kept in history
+const DefaultMaxEntries = 10000
+
+// Dedupe policies for repeated commands
+const (
+	DedupeNone        = "none"        // keep every run
+	DedupeConsecutive = "consecutive" // collapse immediate repeats
+	DedupeAll         = "all"         // keep only the latest run of each command
+)
+
+// DedupePolicies lists the valid dedupe policies
+var DedupePolicies = []string{DedupeNone, DedupeConsecutive, DedupeAll}
+
+// IsValidDedupe reports whether policy is a known dedupe policy (empty means the default)
+func IsValidDedupe(policy string) bool {
+	if policy == "" {
+		return true
+	}
+	for _, p := range DedupePolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// History manages command history, optionally persisted to a Store
 type History struct {
-	commands []string
+	entries  []Entry
 	position int
 	maxSize  int
+	dedupe   string
+	store    *Store
 }
 
 // NewHistory creates a new history manager
 func NewHistory(maxSize int) *History {
 	return &History{
-		commands: make([]string, 0),
+		entries:  make([]Entry, 0),
 		position: -1,
 		maxSize:  maxSize,
+		dedupe:   DedupeConsecutive,
+	}
+}
+
+// SetMaxSize sets the number of commands kept, dropping the oldest if needed
+func (h *History) SetMaxSize(maxSize int) {
+	h.maxSize = maxSize
+	h.entries = trim(h.entries, maxSize)
+	h.position = len(h.entries)
+}
+
+// SetDedupe sets the dedupe policy for repeated commands
+func (h *History) SetDedupe(policy string) {
+	if policy == "" {
+		policy = DedupeConsecutive
 	}
+	h.dedupe = policy
+	h.entries = dedupe(h.entries, policy)
+	h.position = len(h.entries)
+}
+
+// Open attaches a store, loading its entries and compacting the file if it
+// has grown past the size limit. Recorded entries are appended to the store.
+func (h *History) Open(store *Store) error {
+	h.store = store
+	entries, err := store.Load()
+	if err != nil {
+		return err
+	}
+	h.entries = trim(dedupe(entries, h.dedupe), h.maxSize)
+	h.position = len(h.entries)
+	return store.Compact(h.maxSize, h.dedupe)
 }
 
 // Add adds a command to history
 func (h *History) Add(command string) {
-	if command == "" {
-		return
+	h.insert(Entry{Command: command, Time: time.Now()})
+}
+
+// Start adds a command that is still running, so it can be recalled before
+// it finishes. It is kept in memory only until Record completes it.
+func (h *History) Start(command string) {
+	h.insert(Entry{Command: command, Time: time.Now(), running: true})
+}
+
+// Record adds an entry to history and appends it to the store, if any.
+// The latest running entry for the same command is completed in place
+// instead of adding another.
+func (h *History) Record(entry Entry) error {
+	if entry.Command == "" {
+		return nil
+	}
+	if i := h.findRunning(entry.Command); i >= 0 {
+		h.entries[i] = entry
+	} else {
+		h.insert(entry)
+	}
+	if h.store == nil {
+		return nil
 	}
+	return h.store.Append(entry)
+}
 
-	// Don't add duplicates consecutively
-	if len(h.commands) > 0 && h.commands[len(h.commands)-1] == command {
-		h.position = len(h.commands)
+// insert adds an entry to memory, applying the dedupe policy and size limit
+func (h *History) insert(entry Entry) {
+	if entry.Command == "" {
 		return
 	}
 
-	h.commands = append(h.commands, command)
+	switch h.dedupe {
+	case DedupeConsecutive:
+		// Don't add duplicates consecutively; keep the latest run's details
+		if len(h.entries) > 0 && h.entries[len(h.entries)-1].Command == entry.Command {
+			h.entries[len(h.entries)-1] = entry
+			h.position = len(h.entries)
+			return
+		}
+	case DedupeAll:
+		h.entries = removeCommand(h.entries, entry.Command)
+	}
+
+	h.entries = append(h.entries, entry)
 
 	// Trim if exceeds max size
-	if len(h.commands) > h.maxSize {
-		h.commands = h.commands[1:]
-	}
+	h.entries = trim(h.entries, h.maxSize)
 
 	// Reset position to end
-	h.position = len(h.commands)
+	h.position = len(h.entries)
+}
+
+// findRunning returns the index of the latest running entry for command, or -1
+func (h *History) findRunning(command string) int {
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if h.entries[i].running && h.entries[i].Command == command {
+			return i
+		}
+	}
+	return -1
 }
 
 // Previous returns the previous command in history
 func (h *History) Previous() string {
-	if len(h.commands) == 0 {
+	if len(h.entries) == 0 {
 		return ""
 	}
 
@@ -49,8 +153,8 @@ func (h *History) Previous() string {
 		h.position--
 	}
 
-	if h.position >= 0 && h.position < len(h.commands) {
-		return h.commands[h.position]
+	if h.position >= 0 && h.position < len(h.entries) {
+		return h.entries[h.position].Command
 	}
 
 	return ""
@@ -58,56 +162,114 @@ func (h *History) Previous() string {
 
 // Next returns the next command in history
 func (h *History) Next() string {
-	if len(h.commands) == 0 {
+	if len(h.entries) == 0 {
 		return ""
 	}
 
-	if h.position < len(h.commands)-1 {
+	if h.position < len(h.entries)-1 {
 		h.position++
-		return h.commands[h.position]
+		return h.entries[h.position].Command
 	}
 
 	// At the end, return empty to allow new input
-	h.position = len(h.commands)
+	h.position = len(h.entries)
 	return ""
 }
 
 // Reset resets the position to the end
 func (h *History) Reset() {
-	h.position = len(h.commands)
+	h.position = len(h.entries)
 }
 
 // GetAll returns all commands in history
 func (h *History) GetAll() []string {
-	return h.commands
+	commands := make([]string, len(h.entries))
+	for i, entry := range h.entries {
+		commands[i] = entry.Command
+	}
+	return commands
+}
+
+// Entries returns all entries in history, oldest first
+func (h *History) Entries() []Entry {
+	return h.entries
 }
 
 // Len returns the number of commands in history
 func (h *History) Len() int {
-	return len(h.commands)
+	return len(h.entries)
 }
 
 // Clear clears all history
 func (h *History) Clear() {
-	h.commands = make([]string, 0)
+	h.entries = make([]Entry, 0)
 	h.position = -1
 }
 
 // Search finds commands containing the query
 func (h *History) Search(query string) []string {
 	if query == "" {
-		return h.commands
+		return h.GetAll()
 	}
 
 	var matches []string
-	for _, cmd := range h.commands {
-		if containsIgnoreCase(cmd, query) {
-			matches = append(matches, cmd)
+	for _, entry := range h.entries {
+		if containsIgnoreCase(entry.Command, query) {
+			matches = append(matches, entry.Command)
 		}
 	}
 	return matches
 }
 
+// dedupe removes repeated commands from entries according to policy
+func dedupe(entries []Entry, policy string) []Entry {
+	var result []Entry
+	switch policy {
+	case DedupeNone:
+		return entries
+	case DedupeAll:
+		// Walk backwards so the latest run of each command is kept
+		seen := make(map[string]bool)
+		for i := len(entries) - 1; i >= 0; i-- {
+			if !seen[entries[i].Command] {
+				seen[entries[i].Command] = true
+				result = append(result, entries[i])
+			}
+		}
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	default:
+		for _, entry := range entries {
+			if n := len(result); n > 0 && result[n-1].Command == entry.Command {
+				result[n-1] = entry
+				continue
+			}
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// trim keeps the newest maxSize entries
+func trim(entries []Entry, maxSize int) []Entry {
+	if maxSize > 0 && len(entries) > maxSize {
+		return entries[len(entries)-maxSize:]
+	}
+	return entries
+}
+
+// removeCommand returns entries without the runs of command
+func removeCommand(entries []Entry, command string) []Entry {
+	result := entries[:0]
+	for _, entry := range entries {
+		if entry.Command != command {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
 func containsIgnoreCase(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
 		if equalFoldAt(s, substr, i) {
@@ -0,0 +1,99 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	commands := []string{"ls", "ls", "pwd", "ls", "git status", "git status"}
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{DedupeNone, []string{"ls", "ls", "pwd", "ls", "git status", "git status"}},
+		{DedupeConsecutive, []string{"ls", "pwd", "ls", "git status"}},
+		{DedupeAll, []string{"pwd", "ls", "git status"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			h := NewHistory(100)
+			h.SetDedupe(tt.policy)
+			for _, command := range commands {
+				h.Add(command)
+			}
+			if got := h.GetAll(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAll() = %q, want %q", got, tt.want)
+			}
+
+			var entries []Entry
+			for i, command := range commands {
+				entries = append(entries, testEntry(command, i))
+			}
+			if got := commandsOf(dedupe(entries, tt.policy)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dedupe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupeKeepsLatestRun(t *testing.T) {
+	entries := []Entry{testEntry("make", 1), testEntry("ls", 2), testEntry("make", 3)}
+	got := dedupe(entries, DedupeAll)
+	if want := []Entry{entries[1], entries[2]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupe() = %+v, want %+v", got, want)
+	}
+}
+
+func TestHistoryOpen(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), FileName))
+	for i, command := range []string{"a", "b", "b", "c", "d"} {
+		if err := store.Append(testEntry(command, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewHistory(3)
+	if err := h.Open(store); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got, want := h.GetAll(), []string{"b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAll() = %q, want %q", got, want)
+	}
+	if got := h.Previous(); got != "d" {
+		t.Errorf("Previous() = %q, want d", got)
+	}
+}
+
+func TestHistoryStartAndRecord(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), FileName))
+	h := NewHistory(100)
+	if err := h.Open(store); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Start("make test")
+	if got := h.Previous(); got != "make test" {
+		t.Errorf("Previous() = %q while running, want make test", got)
+	}
+	if entries, _ := store.Load(); len(entries) != 0 {
+		t.Errorf("running command was written to the file: %+v", entries)
+	}
+
+	h.Add("ls")
+	finished := testEntry("make test", 7)
+	if err := h.Record(finished); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if got, want := h.Entries(), []Entry{finished, {Command: "ls", Time: h.Entries()[1].Time}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() = %+v, want %+v", got, want)
+	}
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(entries, []Entry{finished}) {
+		t.Errorf("Load() = %+v, want %+v", entries, []Entry{finished})
+	}
+}
@@ -0,0 +1,224 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SearchResult is a history command matching a search query
+type SearchResult struct {
+	// Entry is the latest run of the command
+	Entry Entry
+
+	// Runs and Failures count the runs of the command in history
+	Runs     int
+	Failures int
+
+	// Positions are the rune indexes of Entry.Command that matched the query
+	Positions []int
+
+	Score float64
+}
+
+// Ranking weights: a good text match matters most, then recency, then success
+const (
+	recencyWeight   = 40.0
+	recencyHalfLife = 3 * 24 * time.Hour
+	successWeight   = 20.0
+	frequencyWeight = 5.0
+)
+
+// SearchRanked fuzzy-matches query against history, returning each matching
+// command once, best first. Every word of the query must appear in the
+// command, in order but not necessarily contiguous. Recent and successful
+// commands rank higher. An empty query returns all commands by recency.
+func (h *History) SearchRanked(query string, now time.Time, limit int) []SearchResult {
+	words := strings.Fields(strings.ToLower(query))
+
+	// Group runs by command, newest first
+	var results []SearchResult
+	index := make(map[string]int)
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		entry := h.entries[i]
+		if j, ok := index[entry.Command]; ok {
+			if j < 0 {
+				continue
+			}
+			results[j].Runs++
+			if entry.ExitCode != 0 {
+				results[j].Failures++
+			}
+			continue
+		}
+
+		score, positions, ok := matchWords(words, entry.Command)
+		if !ok {
+			// Mark the command as seen so older runs are skipped cheaply
+			index[entry.Command] = -1
+			continue
+		}
+		result := SearchResult{Entry: entry, Runs: 1, Positions: positions, Score: score}
+		if entry.ExitCode != 0 {
+			result.Failures = 1
+		}
+		index[entry.Command] = len(results)
+		results = append(results, result)
+	}
+
+	for i := range results {
+		results[i].Score += rankBonus(results[i], now)
+	}
+	// Stable so equal scores keep the newest first
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// rankBonus scores how recent, successful and frequently run a command is
+func rankBonus(r SearchResult, now time.Time) float64 {
+	age := now.Sub(r.Entry.Time)
+	if age < 0 {
+		age = 0
+	}
+	recency := math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+
+	success := 1.0 - float64(r.Failures)/float64(r.Runs)
+	if r.Entry.ExitCode != 0 {
+		// The latest run failing weighs more than older failures
+		success /= 2
+	}
+
+	return recencyWeight*recency + successWeight*success + frequencyWeight*math.Log1p(float64(r.Runs-1))
+}
+
+// matchWords matches every query word against command, returning the text
+// match score and the matched rune positions
+func matchWords(words []string, command string) (float64, []int, bool) {
+	if len(words) == 0 {
+		return 0, nil, true
+	}
+
+	target := []rune(strings.ToLower(command))
+	if len(target) != len([]rune(command)) {
+		// Lowercasing changed the length; fall back to per-rune folding
+		target = []rune(command)
+		for i, r := range target {
+			target[i] = unicode.ToLower(r)
+		}
+	}
+
+	var total float64
+	matched := make(map[int]bool)
+	for _, word := range words {
+		score, positions, ok := matchWord([]rune(word), target)
+		if !ok {
+			return 0, nil, false
+		}
+		total += score
+		for _, p := range positions {
+			matched[p] = true
+		}
+	}
+
+	positions := make([]int, 0, len(matched))
+	for p := range matched {
+		positions = append(positions, p)
+	}
+	sort.Ints(positions)
+	return total, positions, true
+}
+
+// matchWord scores a single query word: a substring match beats a scattered
+// subsequence, and matches at the start of a word score higher
+func matchWord(word, target []rune) (float64, []int, bool) {
+	if idx := indexRunes(target, word); idx >= 0 {
+		score := 100.0
+		if isWordStart(target, idx) {
+			score += 20
+		}
+		if idx == 0 {
+			score += 10
+		}
+		positions := make([]int, len(word))
+		for i := range word {
+			positions[i] = idx + i
+		}
+		return score, positions, true
+	}
+
+	// Subsequence: find the first match end going forward, then walk back from
+	// it to get the tightest window, e.g. "klogs" matches "k...logs" in
+	// "kubectl logs" rather than the "l" of "kubectl"
+	end := 0
+	for _, r := range word {
+		for end < len(target) && target[end] != r {
+			end++
+		}
+		if end == len(target) {
+			return 0, nil, false
+		}
+		end++
+	}
+	positions := make([]int, len(word))
+	ti := end - 1
+	for i := len(word) - 1; i >= 0; i-- {
+		for target[ti] != word[i] {
+			ti--
+		}
+		positions[i] = ti
+		ti--
+	}
+
+	// Reward consecutive runs and word starts, penalize gaps
+	score := 50.0
+	for i, p := range positions {
+		if i > 0 {
+			if gap := p - positions[i-1]; gap == 1 {
+				score += 5
+			} else {
+				score -= float64(gap) / 4
+			}
+		}
+		if isWordStart(target, p) {
+			score += 6
+		}
+	}
+	return math.Max(score, 1), positions, true
+}
+
+// indexRunes returns the index of sub in s, or -1
+func indexRunes(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// isWordStart reports whether the rune at i starts a word of a command line
+func isWordStart(s []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	switch s[i-1] {
+	case ' ', '-', '/', '.', '_', '=', ':', '@':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/duladissa/architerm/internal/filelock"
+)
+
+// FileName is the name of the history file in the data directory
+const FileName = "history.jsonl"
+
+// Entry is a command recorded in history
+type Entry struct {
+	Command    string    `json:"command"`
+	Time       time.Time `json:"time"`
+	Cwd        string    `json:"cwd,omitempty"`
+	Target     string    `json:"target,omitempty"` // Empty for commands run locally
+	ExitCode   int       `json:"exit_code"`
+	DurationMs int64     `json:"duration_ms"`
+	Session    string    `json:"session,omitempty"`
+
+	// running marks an entry added by Start whose command has not finished
+	running bool
+}
+
+// Store persists history entries as JSON lines in an append-only file.
+// Writes are serialized across processes with a lock file, so several
+// archiTerm instances can share one history file.
+type Store struct {
+	path string
+}
+
+// NewStore creates a store for the history file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the path of the history file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// GetDataDir returns the user data directory (~/.local/share/architerm, or
+// $XDG_DATA_HOME/architerm; %LocalAppData%\architerm on Windows)
+func GetDataDir() string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return filepath.Join(dir, "architerm")
+		}
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "architerm")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "architerm")
+}
+
+// DefaultPath returns the default history file path
+func DefaultPath() string {
+	return filepath.Join(GetDataDir(), FileName)
+}
+
+// NewSessionID returns a random ID identifying one archiTerm session
+func NewSessionID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// Load reads all entries from the history file, skipping malformed lines.
+// A missing file is not an error.
+func (s *Store) Load() ([]Entry, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		// A partially written line from a crashed process is skipped
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Command == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Append adds an entry to the end of the history file
+func (s *Store) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	return s.withLock(func() error {
+		file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(line); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	})
+}
+
+// Compact rewrites the history file with duplicates removed per policy and
+// only the newest maxEntries kept. To avoid rewriting the file on every start,
+// nothing is done until the file holds a quarter more entries than allowed.
+func (s *Store) Compact(maxEntries int, policy string) error {
+	if maxEntries <= 0 {
+		return nil
+	}
+	return s.withLock(func() error {
+		entries, err := s.Load()
+		if err != nil {
+			return err
+		}
+		if len(entries) <= maxEntries+maxEntries/4 {
+			return nil
+		}
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		for _, entry := range trim(dedupe(entries, policy), maxEntries) {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return filelock.WriteFile(s.path, buf.Bytes())
+	})
+}
+
+// withLock runs fn while holding the history file's lock
+func (s *Store) withLock(fn func() error) error {
+	unlock, err := filelock.Lock(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
@@ -0,0 +1,164 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testEntry(command string, minute int) Entry {
+	return Entry{
+		Command:    command,
+		Time:       time.Date(2026, 2, 20, 10, minute, 0, 0, time.UTC),
+		Cwd:        "/home/me/app",
+		ExitCode:   minute % 2,
+		DurationMs: 812,
+		Session:    "8f2c1a9e0b7d4c36",
+	}
+}
+
+func commandsOf(entries []Entry) []string {
+	commands := make([]string, len(entries))
+	for i, entry := range entries {
+		commands[i] = entry.Command
+	}
+	return commands
+}
+
+func TestStoreAppendAndLoad(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "data", FileName))
+
+	entries, err := store.Load()
+	if err != nil || entries != nil {
+		t.Fatalf("Load() of a missing file = %v, %v; want nothing", entries, err)
+	}
+
+	want := []Entry{testEntry("kubectl get pods", 1), testEntry("git status", 2)}
+	for _, entry := range want {
+		if err := store.Append(entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	entries, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Load() = %+v, want %+v", entries, want)
+	}
+}
+
+func TestStoreLoadSkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	data := `{"command":"ls","time":"2026-02-20T10:15:04Z","exit_code":0,"duration_ms":3}
+not json
+{"command":"","time":"2026-02-20T10:15:05Z"}
+
+{"command":"pwd","time":"2026-02-20T10:15:06Z","exit_code":0,"duration_ms":1}
+{"command":"git pu`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := NewStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := commandsOf(entries), []string{"ls", "pwd"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %q, want %q", got, want)
+	}
+}
+
+func TestStoreConcurrentAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	const writers, perWriter = 8, 50
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// A store per writer, like separate archiTerm instances
+			store := NewStore(path)
+			for i := 0; i < perWriter; i++ {
+				if err := store.Append(testEntry(fmt.Sprintf("echo %d-%d", w, i), i)); err != nil {
+					t.Errorf("Append() error = %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	entries, err := NewStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != writers*perWriter {
+		t.Errorf("Load() returned %d entries, want %d", len(entries), writers*perWriter)
+	}
+}
+
+func TestStoreLockBlocksWriters(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), FileName))
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	go store.withLock(func() error {
+		close(locked)
+		<-release
+		return nil
+	})
+	<-locked
+
+	done := make(chan error)
+	go func() {
+		done <- NewStore(store.Path()).Append(testEntry("ls", 1))
+	}()
+	select {
+	case <-done:
+		t.Fatal("Append() did not wait for the lock")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+}
+
+func TestStoreCompact(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		policy   string
+		want     []string
+	}{
+		{"within slack", []string{"a", "b", "c", "d", "e"}, DedupeNone, []string{"a", "b", "c", "d", "e"}},
+		{"keeps newest", []string{"a", "b", "c", "d", "e", "f"}, DedupeNone, []string{"c", "d", "e", "f"}},
+		{"consecutive", []string{"a", "b", "b", "c", "a", "a", "d"}, DedupeConsecutive, []string{"b", "c", "a", "d"}},
+		{"all", []string{"a", "b", "a", "c", "b", "d", "e"}, DedupeAll, []string{"c", "b", "d", "e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(filepath.Join(t.TempDir(), FileName))
+			for i, command := range tt.commands {
+				if err := store.Append(testEntry(command, i)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := store.Compact(4, tt.policy); err != nil {
+				t.Fatalf("Compact() error = %v", err)
+			}
+			entries, err := store.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := commandsOf(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("after Compact() the file holds %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
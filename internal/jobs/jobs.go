@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/duladissa/architerm/internal/executor"
+)
+
+// Job statuses
+const (
+	StatusRunning   = "running"
+	StatusDone      = "done"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+	StatusTimedOut  = "timed out"
+)
+
+// Job is a command running in the background
+type Job struct {
+	ID        int
+	Command   string
+	Dir       string // Working directory the command runs in
+	Target    string // Target the command runs on; empty means locally
+	StartTime time.Time
+
+	// Result is set when the command has finished
+	Result *executor.Result
+
+	// Cancelled is set when the job was asked to stop
+	Cancelled bool
+
+	chunks []executor.Chunk
+	size   int // Bytes of output in chunks
+	cancel context.CancelFunc
+}
+
+// Status returns the job's status
+func (j *Job) Status() string {
+	switch {
+	case j.Result == nil:
+		return StatusRunning
+	case j.Result.TimedOut:
+		return StatusTimedOut
+	case j.Cancelled || j.Result.Cancelled:
+		return StatusCancelled
+	case j.Result.ExitCode != 0:
+		return StatusFailed
+	}
+	return StatusDone
+}
+
+// Running reports whether the command is still running
+func (j *Job) Running() bool {
+	return j.Result == nil
+}
+
+// Elapsed returns how long the job has been running, or ran for
+func (j *Job) Elapsed(now time.Time) time.Duration {
+	if j.Result != nil {
+		return j.Result.Duration
+	}
+	return now.Sub(j.StartTime)
+}
+
+// Output returns the output received so far (the tail, for long outputs)
+func (j *Job) Output() string {
+	return executor.JoinChunks(j.chunks, true, true)
+}
+
+// Chunks returns the output received so far, tagged by stream
+func (j *Job) Chunks() []executor.Chunk {
+	return j.chunks
+}
+
+// LastLine returns the last non-empty line of output
+func (j *Job) LastLine() string {
+	output := j.Output()
+	if j.Result != nil && output == "" {
+		output = j.Result.Output
+	}
+	lines := strings.Split(strings.TrimRight(output, "\r\n"), "\n")
+	line := lines[len(lines)-1]
+	// Progress output redraws the line with carriage returns
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	return line
+}
+
+// Append adds output received from the command
+func (j *Job) Append(chunk executor.Chunk) {
+	// Keep no more than the finished command's result will
+	j.chunks, j.size, _ = executor.TrimChunks(append(j.chunks, chunk), j.size+len(chunk.Text), 0)
+}
+
+// Cancel asks the command to stop
+func (j *Job) Cancel() {
+	if j.Running() && j.cancel != nil {
+		j.Cancelled = true
+		j.cancel()
+	}
+}
+
+// Manager numbers and tracks jobs. It is not safe for concurrent use; the
+// TUI updates it from its event loop.
+type Manager struct {
+	jobs   []*Job
+	nextID int
+}
+
+// NewManager creates an empty job manager
+func NewManager() *Manager {
+	return &Manager{nextID: 1}
+}
+
+// Add registers a job for command run in dir on target and returns it with a context
+// that is cancelled when the job is
+func (m *Manager) Add(command, dir, target string) (*Job, context.Context) {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		ID:        m.nextID,
+		Command:   command,
+		Dir:       dir,
+		Target:    target,
+		StartTime: time.Now(),
+		cancel:    cancel,
+	}
+	m.nextID++
+	m.jobs = append(m.jobs, job)
+	return job, ctx
+}
+
+// Finish records the result of a job
+func (m *Manager) Finish(id int, result *executor.Result) *Job {
+	job := m.Get(id)
+	if job == nil {
+		return nil
+	}
+	job.Result = result
+	job.cancel()
+	return job
+}
+
+// Get returns the job with the given ID, or nil
+func (m *Manager) Get(id int) *Job {
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// All returns the jobs in the order they were started
+func (m *Manager) All() []*Job {
+	return m.jobs
+}
+
+// Running returns the number of running jobs
+func (m *Manager) Running() int {
+	count := 0
+	for _, job := range m.jobs {
+		if job.Running() {
+			count++
+		}
+	}
+	return count
+}
+
+// Remove drops a finished job from the list. Running jobs are kept.
+func (m *Manager) Remove(id int) bool {
+	for i, job := range m.jobs {
+		if job.ID == id && !job.Running() {
+			m.jobs = append(m.jobs[:i], m.jobs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveFinished drops all finished jobs from the list
+func (m *Manager) RemoveFinished() {
+	var running []*Job
+	for _, job := range m.jobs {
+		if job.Running() {
+			running = append(running, job)
+		}
+	}
+	m.jobs = running
+}
+
+// CancelAll asks every running job to stop
+func (m *Manager) CancelAll() {
+	for _, job := range m.jobs {
+		job.Cancel()
+	}
+}
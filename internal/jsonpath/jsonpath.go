@@ -0,0 +1,159 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Eval evaluates a JSONPath against a JSON document. Supported syntax:
+// "$", ".field", "['field']", "[index]" and "[*]". Multiple results are
+// joined with spaces; strings are returned unquoted.
+func Eval(document, path string) (string, error) {
+	var root interface{}
+	if err := json.Unmarshal([]byte(document), &root); err != nil {
+		return "", fmt.Errorf("output is not valid JSON: %w", err)
+	}
+
+	segments, err := parse(path)
+	if err != nil {
+		return "", err
+	}
+
+	nodes := []interface{}{root}
+	for _, seg := range segments {
+		var next []interface{}
+		for _, node := range nodes {
+			next = append(next, seg.apply(node)...)
+		}
+		nodes = next
+	}
+	if len(nodes) == 0 {
+		return "", fmt.Errorf("no match for %s", path)
+	}
+
+	values := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		values = append(values, formatJSONValue(node))
+	}
+	return strings.Join(values, " "), nil
+}
+
+// pathSegment is a single step of a parsed JSONPath
+type pathSegment struct {
+	field    string
+	index    int
+	wildcard bool
+	isIndex  bool
+}
+
+// apply returns the child nodes selected by the segment
+func (s pathSegment) apply(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			out := make([]interface{}, 0, len(v))
+			for _, child := range v {
+				out = append(out, child)
+			}
+			return out
+		}
+		if child, ok := v[s.field]; ok && !s.isIndex {
+			return []interface{}{child}
+		}
+	case []interface{}:
+		if s.wildcard {
+			return v
+		}
+		if s.isIndex {
+			idx := s.index
+			if idx < 0 {
+				idx += len(v)
+			}
+			if idx >= 0 && idx < len(v) {
+				return []interface{}{v[idx]}
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks that a JSONPath expression is supported
+func Validate(path string) error {
+	_, err := parse(path)
+	return err
+}
+
+// parse splits a JSONPath expression into segments
+func parse(path string) ([]pathSegment, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("jsonpath %q must start with $", path)
+	}
+
+	var segments []pathSegment
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("jsonpath %q has an empty field name", path)
+			}
+			if name == "*" {
+				segments = append(segments, pathSegment{wildcard: true})
+			} else {
+				segments = append(segments, pathSegment{field: name})
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath %q has an unclosed bracket", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, "\""):
+				segments = append(segments, pathSegment{field: strings.Trim(inner, "'\"")})
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("jsonpath %q has an invalid index %q", path, inner)
+				}
+				segments = append(segments, pathSegment{index: idx, isIndex: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("jsonpath %q is invalid near %q", path, rest)
+		}
+	}
+	return segments, nil
+}
+
+// formatJSONValue renders a JSON value as plain text
+func formatJSONValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	}
+}
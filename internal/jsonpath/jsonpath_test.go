@@ -0,0 +1,80 @@
+package jsonpath
+
+import "testing"
+
+const pods = `{
+	"kind": "List",
+	"items": [
+		{"metadata": {"name": "web-1", "labels": {"app.kubernetes.io/name": "web"}}, "spec": {"replicas": 3, "paused": false}},
+		{"metadata": {"name": "web-2", "labels": {"app.kubernetes.io/name": "web"}}, "spec": {"replicas": 1.5, "paused": true}}
+	]
+}`
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$.kind", "List"},
+		{"$.items[0].metadata.name", "web-1"},
+		{"$.items[-1].metadata.name", "web-2"},
+		{"$['items'][1]['metadata'].name", "web-2"},
+		{"$.items[0].metadata.labels['app.kubernetes.io/name']", "web"},
+		{"$.items[*].metadata.name", "web-1 web-2"},
+		{"$.items.*.spec.replicas", "3 1.5"},
+		{"$.items[1].spec.paused", "true"},
+		{"$.items[0].spec", `{"paused":false,"replicas":3}`},
+	}
+	for _, tt := range tests {
+		got, err := Eval(pods, tt.path)
+		if err != nil {
+			t.Errorf("Eval(%q) error = %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		path     string
+	}{
+		{"not JSON", "NAME READY\nweb-1 1/1", "$.kind"},
+		{"missing field", pods, "$.status"},
+		{"index out of range", pods, "$.items[2]"},
+		{"field of an array", pods, "$.items.name"},
+		{"invalid path", pods, "items[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Eval(tt.document, tt.path); err == nil {
+				t.Errorf("Eval(%q) = %q, want an error", tt.path, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"$", true},
+		{"$.a.b[0]['c d'][*]", true},
+		{" $.a ", true},
+		{"a.b", false},
+		{"$.a..b", false},
+		{"$.a[0", false},
+		{"$.a[x]", false},
+		{"$a", false},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.path); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) error = %v, want valid %v", tt.path, err, tt.valid)
+		}
+	}
+}
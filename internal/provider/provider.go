@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duladissa/architerm/internal/executor"
+)
+
+// DefaultTimeout bounds how long a provider command may run
+const DefaultTimeout = 10 * time.Second
+
+// cacheEntry holds the candidates produced by a provider command
+type cacheEntry struct {
+	values    []string
+	expiresAt time.Time
+}
+
+// Resolver runs placeholder provider commands and caches their results
+type Resolver struct {
+	mu      sync.Mutex
+	cache   map[string]cacheEntry
+	timeout time.Duration
+	opts    executor.Options // Where and how provider commands run
+}
+
+// NewResolver creates a new provider resolver
+func NewResolver() *Resolver {
+	return &Resolver{
+		cache:   make(map[string]cacheEntry),
+		timeout: DefaultTimeout,
+	}
+}
+
+// Cached returns cached candidates for a resolved provider command, if still fresh
+func (r *Resolver) Cached(command string) ([]string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.cache[command]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.values, true
+}
+
+// Resolve returns the candidates for a resolved provider command, running it
+// through a dedicated executor when the cache is empty or expired
+func (r *Resolver) Resolve(command string, ttl time.Duration) ([]string, error) {
+	if values, ok := r.Cached(command); ok {
+		return values, nil
+	}
+
+	exec := executor.NewExecutor()
+	r.mu.Lock()
+	exec.Options = r.opts
+	r.mu.Unlock()
+
+	timer := time.AfterFunc(r.timeout, exec.Cancel)
+	result := exec.Execute(command)
+	timer.Stop()
+
+	if result.ExitCode != 0 {
+		msg := strings.TrimSpace(result.Output)
+		if msg == "" {
+			msg = fmt.Sprintf("exit code %d", result.ExitCode)
+		}
+		return nil, fmt.Errorf("provider %q failed: %s", command, firstLine(msg))
+	}
+
+	values := parseCandidates(result.Stdout())
+
+	r.mu.Lock()
+	r.cache[command] = cacheEntry{
+		values:    values,
+		expiresAt: time.Now().Add(ttl),
+	}
+	r.mu.Unlock()
+
+	return values, nil
+}
+
+// Invalidate drops all cached candidates
+func (r *Resolver) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = make(map[string]cacheEntry)
+}
+
+// SetOptions sets the working directory, environment, shell and target
+// provider commands run with, dropping the candidates cached for the previous
+// ones. Timeouts of opts are ignored.
+func (r *Resolver) SetOptions(opts executor.Options) {
+	opts = executor.Options{Dir: opts.Dir, Env: opts.Env, Shell: opts.Shell, Target: opts.Target, SessionEnv: opts.SessionEnv}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if opts.Dir != r.opts.Dir || opts.Shell != r.opts.Shell || opts.Target != r.opts.Target ||
+		!slices.Equal(opts.Env, r.opts.Env) || !slices.Equal(opts.SessionEnv, r.opts.SessionEnv) {
+		r.opts = opts
+		r.cache = make(map[string]cacheEntry)
+	}
+}
+
+// parseCandidates splits provider output into unique, non-empty lines
+func parseCandidates(output string) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		values = append(values, line)
+	}
+	return values
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,215 @@
+package runbook
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/duladissa/architerm/internal/jsonpath"
+)
+
+// StepStatus is the state of a runbook step
+type StepStatus int
+
+const (
+	StepPending StepStatus = iota
+	StepRunning
+	StepOK
+	StepFailed
+	StepSkipped
+)
+
+// String returns a short label for the status
+func (s StepStatus) String() string {
+	switch s {
+	case StepRunning:
+		return "running"
+	case StepOK:
+		return "ok"
+	case StepFailed:
+		return "failed"
+	case StepSkipped:
+		return "skipped"
+	default:
+		return "pending"
+	}
+}
+
+// Run tracks the progress of a single runbook execution
+type Run struct {
+	Runbook commands.Runbook
+	Vars    map[string]string
+	Status  []StepStatus
+	Errors  []string // Per-step failure reason
+	Current int      // Index of the next step to run
+	Paused  bool
+}
+
+// NewRun starts a runbook with the given input values
+func NewRun(rb commands.Runbook, inputs map[string]string) *Run {
+	vars := make(map[string]string, len(inputs))
+	for k, v := range inputs {
+		vars[k] = v
+	}
+	return &Run{
+		Runbook: rb,
+		Vars:    vars,
+		Status:  make([]StepStatus, len(rb.Steps)),
+		Errors:  make([]string, len(rb.Steps)),
+		Current: 0,
+	}
+}
+
+// Done returns true when every step has finished or been skipped
+func (r *Run) Done() bool {
+	return r.Current >= len(r.Runbook.Steps)
+}
+
+// Blocked returns true if the current step failed and needs a retry or skip
+func (r *Run) Blocked() bool {
+	return !r.Done() && r.Status[r.Current] == StepFailed
+}
+
+// Running returns true while the current step is executing
+func (r *Run) Running() bool {
+	return !r.Done() && r.Status[r.Current] == StepRunning
+}
+
+// CanAdvance returns true if the next step may start automatically
+func (r *Run) CanAdvance() bool {
+	return !r.Done() && !r.Paused && r.Status[r.Current] == StepPending
+}
+
+// CurrentStep returns the step to run next
+func (r *Run) CurrentStep() *commands.RunbookStep {
+	if r.Done() {
+		return nil
+	}
+	return &r.Runbook.Steps[r.Current]
+}
+
+// StepCommand returns the current step's command with variables filled in.
+// Captured values come from command output and are shell-quoted; inputs are
+// filled as typed, like any other command. It fails if the command still
+// references a variable that has no value.
+func (r *Run) StepCommand() (string, error) {
+	step := r.CurrentStep()
+	if step == nil {
+		return "", fmt.Errorf("runbook finished")
+	}
+	captured := r.Runbook.CapturedVars()
+	values := make(map[string]string, len(r.Vars))
+	for name, value := range r.Vars {
+		if captured[name] {
+			value = executor.ShellQuote(value)
+		}
+		values[name] = value
+	}
+	command := commands.FillPlaceholders(step.Command, values)
+
+	var missing []string
+	for _, name := range commands.DetectPlaceholders(step.Command) {
+		if _, ok := r.Vars[name]; !ok && captured[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return command, fmt.Errorf("unresolved variable(s): %s", strings.Join(missing, ", "))
+	}
+	return command, nil
+}
+
+// Start marks the current step as running
+func (r *Run) Start() {
+	if !r.Done() {
+		r.Status[r.Current] = StepRunning
+		r.Errors[r.Current] = ""
+	}
+}
+
+// Fail marks the current step as failed without running it
+func (r *Run) Fail(reason string) {
+	if !r.Done() {
+		r.Status[r.Current] = StepFailed
+		r.Errors[r.Current] = reason
+	}
+}
+
+// Complete records the result of the current step, captures its variables and
+// advances unless the step failed (and does not allow continuing on error)
+func (r *Run) Complete(result *executor.Result) {
+	if r.Done() {
+		return
+	}
+	step := r.Runbook.Steps[r.Current]
+
+	if result.ExitCode != 0 {
+		r.Errors[r.Current] = fmt.Sprintf("exit code %d", result.ExitCode)
+		r.Status[r.Current] = StepFailed
+		if step.ContinueOnError {
+			r.Current++
+		}
+		return
+	}
+
+	// Capture from stdout, so warnings on stderr do not get in the way
+	output := result.Stdout()
+	if output == "" {
+		output = result.Stderr()
+	}
+	for _, c := range step.Capture {
+		value, err := capture(c, output)
+		if err != nil {
+			r.Errors[r.Current] = fmt.Sprintf("capture %s: %v", c.Var, err)
+			r.Status[r.Current] = StepFailed
+			return
+		}
+		r.Vars[c.Var] = value
+	}
+
+	r.Status[r.Current] = StepOK
+	r.Current++
+}
+
+// Skip skips the current step
+func (r *Run) Skip() {
+	if !r.Done() && r.Status[r.Current] != StepRunning {
+		r.Status[r.Current] = StepSkipped
+		r.Current++
+	}
+}
+
+// Retry resets a failed current step so it runs again
+func (r *Run) Retry() {
+	if r.Blocked() {
+		r.Status[r.Current] = StepPending
+		r.Errors[r.Current] = ""
+	}
+}
+
+// TogglePause pauses or resumes automatic advancing between steps
+func (r *Run) TogglePause() {
+	r.Paused = !r.Paused
+}
+
+// capture extracts a variable value from step output
+func capture(c commands.Capture, output string) (string, error) {
+	if c.JSONPath != "" {
+		return jsonpath.Eval(output, c.JSONPath)
+	}
+
+	re, err := regexp.Compile(c.Regex)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex: %w", err)
+	}
+	match := re.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("no match for %s", c.Regex)
+	}
+	if len(match) > 1 {
+		return strings.TrimSpace(match[1]), nil
+	}
+	return strings.TrimSpace(match[0]), nil
+}
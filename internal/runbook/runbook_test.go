@@ -0,0 +1,110 @@
+package runbook
+
+import (
+	"testing"
+
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/executor"
+)
+
+func TestCapture(t *testing.T) {
+	const output = "Deployment created\nrevision: 42\nurl: https://web.example.com \n"
+	tests := []struct {
+		name    string
+		capture commands.Capture
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{"regex group", commands.Capture{Regex: `revision: (\d+)`}, output, "42", false},
+		{"regex whole match", commands.Capture{Regex: `https://\S+`}, output, "https://web.example.com", false},
+		{"regex group is trimmed", commands.Capture{Regex: `url:(.*)`}, output, "https://web.example.com", false},
+		{"regex no match", commands.Capture{Regex: `error: (\w+)`}, output, "", true},
+		{"invalid regex", commands.Capture{Regex: `(`}, output, "", true},
+		{"jsonpath", commands.Capture{JSONPath: "$.status.podIP"}, `{"status": {"podIP": "10.0.0.7"}}`, "10.0.0.7", false},
+		{"jsonpath list", commands.Capture{JSONPath: "$.items[*].name"}, `{"items": [{"name": "a"}, {"name": "b"}]}`, "a b", false},
+		{"jsonpath on text", commands.Capture{JSONPath: "$.name"}, output, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := capture(tt.capture, tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("capture() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("capture() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCapturesIntoLaterSteps(t *testing.T) {
+	rb := commands.Runbook{
+		ID: "deploy",
+		Steps: []commands.RunbookStep{
+			{Name: "apply", Command: "kubectl apply -n NAMESPACE -f app.yaml -o json", Capture: []commands.Capture{{Var: "POD", JSONPath: "$.metadata.name"}}},
+			{Name: "logs", Command: "kubectl logs -n NAMESPACE POD"},
+		},
+	}
+	run := NewRun(rb, map[string]string{"NAMESPACE": "prod"})
+
+	if _, err := run.StepCommand(); err != nil {
+		t.Fatalf("StepCommand() error = %v", err)
+	}
+	run.Start()
+	run.Complete(&executor.Result{Output: `{"metadata": {"name": "web-1"}}`})
+	if run.Status[0] != StepOK {
+		t.Fatalf("step 1 is %s: %s", run.Status[0], run.Errors[0])
+	}
+
+	command, err := run.StepCommand()
+	if err != nil {
+		t.Fatalf("StepCommand() error = %v", err)
+	}
+	if want := "kubectl logs -n prod web-1"; command != want {
+		t.Errorf("StepCommand() = %q, want %q", command, want)
+	}
+}
+
+func TestRunCaptureFailure(t *testing.T) {
+	rb := commands.Runbook{
+		ID: "deploy",
+		Steps: []commands.RunbookStep{
+			{Name: "apply", Command: "make deploy", Capture: []commands.Capture{{Var: "URL", Regex: `url: (\S+)`}}},
+			{Name: "check", Command: "curl URL"},
+		},
+	}
+	run := NewRun(rb, nil)
+
+	run.Start()
+	run.Complete(&executor.Result{Output: "done\n"})
+	if !run.Blocked() || run.Errors[0] == "" {
+		t.Fatalf("capture without a match did not fail the step: %s", run.Status[0])
+	}
+
+	run.Skip()
+	if _, err := run.StepCommand(); err == nil {
+		t.Error("StepCommand() ran a step whose captured variable has no value")
+	}
+}
+
+func TestRunQuotesCapturedValues(t *testing.T) {
+	rb := commands.Runbook{
+		ID: "notify",
+		Steps: []commands.RunbookStep{
+			{Name: "status", Command: "make status", Capture: []commands.Capture{{Var: "STATUS", Regex: `status: (.+)`}}},
+			{Name: "notify", Command: "notify -c CHANNEL STATUS"},
+		},
+	}
+	run := NewRun(rb, map[string]string{"CHANNEL": "#ops"})
+
+	run.Start()
+	run.Complete(&executor.Result{Output: "status: it's done; rm -rf $HOME\n"})
+	command, err := run.StepCommand()
+	if err != nil {
+		t.Fatalf("StepCommand() error = %v", err)
+	}
+	if want := `notify -c #ops 'it'\''s done; rm -rf $HOME'`; command != want {
+		t.Errorf("StepCommand() = %q, want %q", command, want)
+	}
+}
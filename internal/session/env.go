@@ -0,0 +1,228 @@
+package session
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Profile is a named set of environment variables, such as dev or prod
+type Profile struct {
+	Name string
+	Vars map[string]string
+
+	// Protected profiles are highlighted while active, so commands are not
+	// run against them by accident
+	Protected bool
+}
+
+// envName matches a valid environment variable name
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsValidEnvName reports whether name can be used as an environment variable
+func IsValidEnvName(name string) bool {
+	return envName.MatchString(name)
+}
+
+// Export sets variables for the commands of the session. Each argument is
+// NAME=VALUE; a bare NAME keeps the variable if it was unset in the session.
+func (s *Session) Export(args []string) error {
+	for _, arg := range args {
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !IsValidEnvName(name) {
+			return fmt.Errorf("export: not a valid name: %s", name)
+		}
+		delete(s.unset, name)
+		if !hasValue {
+			continue
+		}
+		if s.exports == nil {
+			s.exports = make(map[string]string)
+		}
+		s.exports[name] = os.Expand(value, s.Getenv)
+	}
+	return nil
+}
+
+// Unset removes variables from the environment of the session's commands,
+// including those inherited from archiTerm or set by the profile
+func (s *Session) Unset(names []string) error {
+	for _, name := range names {
+		if !IsValidEnvName(name) {
+			return fmt.Errorf("unset: not a valid name: %s", name)
+		}
+		delete(s.exports, name)
+		if s.unset == nil {
+			s.unset = make(map[string]bool)
+		}
+		s.unset[name] = true
+	}
+	return nil
+}
+
+// Exports lists the variables exported in the session as NAME=VALUE, sorted
+func (s *Session) Exports() []string {
+	var vars []string
+	for name, value := range s.exports {
+		vars = append(vars, name+"="+value)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// SetProfile makes profile the active one; nil turns profiles off. Variables
+// exported in the session still take precedence.
+func (s *Session) SetProfile(profile *Profile) {
+	s.Profile = profile
+}
+
+// Getenv returns the value of a variable as the session's commands see it
+func (s *Session) Getenv(name string) string {
+	if s.unset[name] {
+		return ""
+	}
+	if value, ok := s.exports[name]; ok {
+		return value
+	}
+	if s.Profile != nil {
+		if value, ok := s.Profile.Vars[name]; ok {
+			return value
+		}
+	}
+	return os.Getenv(name)
+}
+
+// Vars returns the variables the session sets over archiTerm's environment,
+// from the active profile and export, as NAME=VALUE sorted by name
+func (s *Session) Vars() []string {
+	vars := make(map[string]string)
+	if s.Profile != nil {
+		for name, value := range s.Profile.Vars {
+			vars[name] = value
+		}
+	}
+	for name, value := range s.exports {
+		vars[name] = value
+	}
+	var env []string
+	for name, value := range vars {
+		if !s.unset[name] {
+			env = append(env, name+"="+value)
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// Environ returns the environment of the session's commands: archiTerm's
+// own, overridden by the active profile and then by exported variables
+func (s *Session) Environ() []string {
+	vars := make(map[string]string)
+	var order []string
+	set := func(name, value string) {
+		if _, ok := vars[name]; !ok {
+			order = append(order, name)
+		}
+		vars[name] = value
+	}
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			set(name, value)
+		}
+	}
+	if s.Profile != nil {
+		for name, value := range s.Profile.Vars {
+			set(name, value)
+		}
+	}
+	for name, value := range s.exports {
+		set(name, value)
+	}
+
+	env := make([]string, 0, len(order))
+	for _, name := range order {
+		if !s.unset[name] {
+			env = append(env, name+"="+vars[name])
+		}
+	}
+	return env
+}
+
+// LoadEnvFile reads NAME=VALUE lines from a .env file. Blank lines, comments
+// and a leading "export " are skipped, and values may be quoted.
+func LoadEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !IsValidEnvName(name) {
+			return nil, fmt.Errorf("%s:%d: expected NAME=VALUE", path, n)
+		}
+		vars[name] = unquote(strings.TrimSpace(value))
+	}
+	return vars, scanner.Err()
+}
+
+// unquote removes matching quotes around a .env value, or a trailing comment
+// from an unquoted one
+func unquote(value string) string {
+	if n := len(value); n >= 2 && value[0] == value[n-1] {
+		switch value[0] {
+		case '\'':
+			return value[1 : n-1]
+		case '"':
+			return strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value[1 : n-1])
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// FindEnvFiles returns the .env files in dir, such as .env and .env.staging,
+// leaving out examples and templates
+func FindEnvFiles(dir string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, ".env*"))
+	var files []string
+	for _, path := range matches {
+		name := filepath.Base(path)
+		if name != ".env" && !strings.HasPrefix(name, ".env.") {
+			continue
+		}
+		switch filepath.Ext(name) {
+		case ".example", ".sample", ".template", ".dist":
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// EnvFileProfileName names the profile of a .env file: "staging" for
+// .env.staging and ".env" for .env itself
+func EnvFileProfileName(path string) string {
+	name := filepath.Base(path)
+	if name == ".env" {
+		return name
+	}
+	return strings.TrimPrefix(name, ".env.")
+}
@@ -0,0 +1,168 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// environ returns the variables of env whose names start with prefix, sorted
+func environ(env []string, prefix string) []string {
+	var vars []string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, prefix) {
+			vars = append(vars, kv)
+		}
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+func TestExportAndUnset(t *testing.T) {
+	t.Setenv("AT_INHERITED", "from-parent")
+	s := &Session{}
+
+	steps := []string{
+		"export AT_REGION=eu-west-1 AT_URL=https://$AT_REGION.example.com",
+		"export AT_QUOTED='two words'",
+		"unset AT_INHERITED",
+	}
+	for _, step := range steps {
+		name, args, ok := ParseBuiltin(step)
+		if !ok {
+			t.Fatalf("ParseBuiltin(%q) is not a built-in", step)
+		}
+		if _, err := s.Run(name, args); err != nil {
+			t.Fatalf("%s: %v", step, err)
+		}
+	}
+
+	want := []string{"AT_QUOTED=two words", "AT_REGION=eu-west-1", "AT_URL=https://eu-west-1.example.com"}
+	if got := s.Exports(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Exports() = %q, want %q", got, want)
+	}
+	if got := environ(s.Environ(), "AT_"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Environ() has %q, want %q", got, want)
+	}
+	if got, err := s.Run("export", nil); err != nil || got != strings.Join(want, " ") {
+		t.Errorf("bare export printed %q, %v", got, err)
+	}
+
+	// A bare export brings back an unset variable; unset removes an export
+	if err := s.Export([]string{"AT_INHERITED"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Unset([]string{"AT_URL"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Getenv("AT_INHERITED"); got != "from-parent" {
+		t.Errorf("Getenv(AT_INHERITED) = %q after export, want from-parent", got)
+	}
+	if got := s.Getenv("AT_URL"); got != "" {
+		t.Errorf("Getenv(AT_URL) = %q after unset, want empty", got)
+	}
+}
+
+func TestExportInvalidName(t *testing.T) {
+	s := &Session{}
+	for _, arg := range []string{"1ABC=x", "A-B=x", "=x"} {
+		if err := s.Export([]string{arg}); err == nil {
+			t.Errorf("Export(%q) succeeded", arg)
+		}
+	}
+	if err := s.Unset([]string{"A.B"}); err == nil {
+		t.Error("Unset(A.B) succeeded")
+	}
+}
+
+func TestProfilePrecedence(t *testing.T) {
+	t.Setenv("AT_STAGE", "parent")
+	t.Setenv("AT_REGION", "parent")
+	s := &Session{}
+	s.SetProfile(&Profile{Name: "prod", Vars: map[string]string{"AT_STAGE": "prod", "AT_REGION": "us-east-1", "AT_ONLY": "x"}})
+	if err := s.Export([]string{"AT_REGION=eu-west-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"AT_ONLY=x", "AT_REGION=eu-west-1", "AT_STAGE=prod"}
+	if got := environ(s.Environ(), "AT_"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Environ() has %q, want %q", got, want)
+	}
+
+	s.SetProfile(nil)
+	want = []string{"AT_REGION=eu-west-1", "AT_STAGE=parent"}
+	if got := environ(s.Environ(), "AT_"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Environ() without a profile has %q, want %q", got, want)
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	data := `# Database
+DB_HOST=localhost
+export DB_PORT=5432
+DB_USER = admin
+DB_PASS='p@ss "word" #1'
+GREETING="hello\nworld \"quoted\""
+PLAIN=value # comment
+HASH=a#b
+EMPTY=
+QUOTE_ONLY="
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatalf("LoadEnvFile() error = %v", err)
+	}
+	want := map[string]string{
+		"DB_HOST":    "localhost",
+		"DB_PORT":    "5432",
+		"DB_USER":    "admin",
+		"DB_PASS":    `p@ss "word" #1`,
+		"GREETING":   "hello\nworld \"quoted\"",
+		"PLAIN":      "value",
+		"HASH":       "a#b",
+		"EMPTY":      "",
+		"QUOTE_ONLY": `"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadEnvFile() = %q\nwant %q", got, want)
+	}
+}
+
+func TestLoadEnvFileErrors(t *testing.T) {
+	for _, line := range []string{"NO_EQUALS", "1BAD=x", "A B=x"} {
+		path := filepath.Join(t.TempDir(), ".env")
+		if err := os.WriteFile(path, []byte("OK=1\n"+line+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadEnvFile(path); err == nil || !strings.Contains(err.Error(), ":2:") {
+			t.Errorf("LoadEnvFile() with %q: error = %v, want one for line 2", line, err)
+		}
+	}
+}
+
+func TestFindEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".env", ".env.staging", ".env.example", ".env.local.sample", ".envrc", "app.env"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, ".env.d"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, path := range FindEnvFiles(dir) {
+		names = append(names, EnvFileProfileName(path))
+	}
+	if want := []string{".env", "staging"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("FindEnvFiles() profiles = %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,274 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Session is the state that shell built-ins change between commands. Every
+// command runs in a new shell, so a cd or export typed into archiTerm is run
+// here instead and passed on to the commands that follow.
+type Session struct {
+	// Dir is the working directory of the commands run in the session
+	Dir string
+
+	// Profile is the active environment profile, if any
+	Profile *Profile
+
+	oldDir  string            // Directory before the last change, for cd -
+	stack   []string          // Directories saved by pushd, the most recent last
+	exports map[string]string // Variables set by export
+	unset   map[string]bool   // Variables removed by unset
+}
+
+// New creates a session starting in the current working directory
+func New() *Session {
+	dir, err := os.Getwd()
+	if err != nil {
+		dir, _ = os.UserHomeDir()
+	}
+	return &Session{Dir: dir}
+}
+
+// Builtins are the commands archiTerm runs itself rather than in a shell
+var Builtins = []string{"cd", "pushd", "popd", "export", "unset"}
+
+// ParseBuiltin splits command into a built-in and its arguments. ok is false
+// for other commands and for built-ins combined with other commands (such as
+// "cd dir && make"), which are left to the shell.
+func ParseBuiltin(command string) (name string, args []string, ok bool) {
+	if strings.ContainsAny(command, ";&|<>`\n") || strings.Contains(command, "$(") {
+		return "", nil, false
+	}
+	words, err := SplitWords(command)
+	if err != nil || len(words) == 0 {
+		return "", nil, false
+	}
+	for _, builtin := range Builtins {
+		if words[0] == builtin {
+			return builtin, words[1:], true
+		}
+	}
+	return "", nil, false
+}
+
+// Run runs a built-in, returning what it prints (the directory stack for
+// pushd and popd, the exported variables for a bare export)
+func (s *Session) Run(name string, args []string) (string, error) {
+	switch name {
+	case "export":
+		if len(args) == 0 {
+			return strings.Join(s.Exports(), " "), nil
+		}
+		return "", s.Export(args)
+	case "unset":
+		return "", s.Unset(args)
+	}
+
+	if len(args) > 1 {
+		return "", fmt.Errorf("%s: too many arguments", name)
+	}
+	arg := ""
+	if len(args) == 1 {
+		arg = args[0]
+	}
+
+	switch name {
+	case "cd":
+		return "", s.Cd(arg)
+	case "pushd":
+		if err := s.Pushd(arg); err != nil {
+			return "", err
+		}
+	case "popd":
+		if arg != "" {
+			return "", fmt.Errorf("popd: arguments are not supported")
+		}
+		if err := s.Popd(); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("%s: not a built-in", name)
+	}
+	return s.Dirs(), nil
+}
+
+// Cd changes the working directory. An empty dir means the home directory
+// and "-" the previous directory.
+func (s *Session) Cd(dir string) error {
+	switch dir {
+	case "":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("cd: %v", err)
+		}
+		dir = home
+	case "-":
+		if s.oldDir == "" {
+			return fmt.Errorf("cd: no previous directory")
+		}
+		dir = s.oldDir
+	}
+	resolved, err := s.resolve(dir)
+	if err != nil {
+		return fmt.Errorf("cd: %v", err)
+	}
+	s.oldDir, s.Dir = s.Dir, resolved
+	return nil
+}
+
+// Pushd saves the working directory on the stack and changes to dir. Without
+// dir it swaps the working directory with the top of the stack.
+func (s *Session) Pushd(dir string) error {
+	if dir == "" {
+		if len(s.stack) == 0 {
+			return fmt.Errorf("pushd: no other directory")
+		}
+		top := len(s.stack) - 1
+		s.oldDir = s.Dir
+		s.Dir, s.stack[top] = s.stack[top], s.Dir
+		return nil
+	}
+	resolved, err := s.resolve(dir)
+	if err != nil {
+		return fmt.Errorf("pushd: %v", err)
+	}
+	s.stack = append(s.stack, s.Dir)
+	s.oldDir, s.Dir = s.Dir, resolved
+	return nil
+}
+
+// Popd changes to the directory on top of the stack and removes it
+func (s *Session) Popd() error {
+	if len(s.stack) == 0 {
+		return fmt.Errorf("popd: directory stack empty")
+	}
+	top := len(s.stack) - 1
+	dir := s.stack[top]
+	s.stack = s.stack[:top]
+	s.oldDir, s.Dir = s.Dir, dir
+	return nil
+}
+
+// Dirs lists the working directory and the stack like the shell's dirs
+func (s *Session) Dirs() string {
+	dirs := []string{Abbreviate(s.Dir)}
+	for i := len(s.stack) - 1; i >= 0; i-- {
+		dirs = append(dirs, Abbreviate(s.stack[i]))
+	}
+	return strings.Join(dirs, " ")
+}
+
+// resolve returns dir as an absolute path relative to the working
+// directory, checking that it is a directory
+func (s *Session) resolve(dir string) (string, error) {
+	dir = os.Expand(ExpandHome(dir), s.Getenv)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(s.Dir, dir)
+	}
+	dir = filepath.Clean(dir)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("no such directory: %s", dir)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("not a directory: %s", dir)
+	}
+	return dir, nil
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// Abbreviate shortens the home directory at the start of dir to ~
+func Abbreviate(dir string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == "/" {
+		return dir
+	}
+	if dir == home {
+		return "~"
+	}
+	if strings.HasPrefix(dir, home+string(filepath.Separator)) {
+		return "~" + dir[len(home):]
+	}
+	return dir
+}
+
+// Short abbreviates dir for narrow places like the prompt, keeping the last
+// element whole and the first letter of the others (~/w/infra/prod becomes
+// ~/w/i/prod) once it is longer than max
+func Short(dir string, max int) string {
+	dir = Abbreviate(dir)
+	if len([]rune(dir)) <= max {
+		return dir
+	}
+	sep := string(filepath.Separator)
+	parts := strings.Split(dir, sep)
+	for i := 0; i < len(parts)-1; i++ {
+		if r := []rune(parts[i]); len(r) > 1 && parts[i] != "~" {
+			if r[0] == '.' && len(r) > 2 {
+				parts[i] = string(r[:2])
+			} else {
+				parts[i] = string(r[:1])
+			}
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// SplitWords splits a command line into words, removing quotes and
+// backslash escapes
+func SplitWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				escaped = true
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
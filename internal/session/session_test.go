@@ -0,0 +1,207 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testHome makes a temporary home directory with the given subdirectories
+// and returns its path
+func testHome(t *testing.T, dirs ...string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(home, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return home
+}
+
+func TestParseBuiltin(t *testing.T) {
+	tests := []struct {
+		command string
+		name    string
+		args    []string
+		ok      bool
+	}{
+		{"cd", "cd", []string{}, true},
+		{"cd /tmp", "cd", []string{"/tmp"}, true},
+		{`cd "my dir"`, "cd", []string{"my dir"}, true},
+		{`cd my\ dir`, "cd", []string{"my dir"}, true},
+		{"pushd ~/src", "pushd", []string{"~/src"}, true},
+		{"  popd", "popd", []string{}, true},
+		{"cd /tmp && make", "", nil, false},
+		{"cd $(git rev-parse --show-toplevel)", "", nil, false},
+		{"cd `pwd`", "", nil, false},
+		{"cdk deploy", "", nil, false},
+		{`cd "unterminated`, "", nil, false},
+		{"ls", "", nil, false},
+	}
+	for _, tt := range tests {
+		name, args, ok := ParseBuiltin(tt.command)
+		if name != tt.name || ok != tt.ok || (ok && !reflect.DeepEqual(args, tt.args)) {
+			t.Errorf("ParseBuiltin(%q) = %q, %q, %v; want %q, %q, %v", tt.command, name, args, ok, tt.name, tt.args, tt.ok)
+		}
+	}
+}
+
+func TestCd(t *testing.T) {
+	home := testHome(t, "src/api", "src/web")
+	if err := os.WriteFile(filepath.Join(home, "notes.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SRC", filepath.Join(home, "src"))
+
+	tests := []struct {
+		name    string
+		from    string
+		dir     string
+		want    string
+		wantErr bool
+	}{
+		{"relative", home, "src/api", filepath.Join(home, "src", "api"), false},
+		{"parent", filepath.Join(home, "src", "api"), "../web", filepath.Join(home, "src", "web"), false},
+		{"absolute", home, filepath.Join(home, "src"), filepath.Join(home, "src"), false},
+		{"home", filepath.Join(home, "src"), "", home, false},
+		{"tilde", filepath.Join(home, "src"), "~", home, false},
+		{"tilde path", "/", "~/src/web", filepath.Join(home, "src", "web"), false},
+		{"variable", home, "$SRC/api", filepath.Join(home, "src", "api"), false},
+		{"missing", home, "nope", home, true},
+		{"file", home, "notes.txt", home, true},
+		{"no previous directory", home, "-", home, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Session{Dir: tt.from}
+			err := s.Cd(tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Cd(%q) error = %v, wantErr %v", tt.dir, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				tt.want = tt.from
+			}
+			if s.Dir != tt.want {
+				t.Errorf("Cd(%q) changed to %q, want %q", tt.dir, s.Dir, tt.want)
+			}
+		})
+	}
+}
+
+func TestCdPrevious(t *testing.T) {
+	home := testHome(t, "src")
+	src := filepath.Join(home, "src")
+	s := &Session{Dir: home}
+	if err := s.Cd("src"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{home, src, home} {
+		if err := s.Cd("-"); err != nil {
+			t.Fatal(err)
+		}
+		if s.Dir != want {
+			t.Errorf("Cd(-) changed to %q, want %q", s.Dir, want)
+		}
+	}
+}
+
+func TestPushdPopd(t *testing.T) {
+	home := testHome(t, "src/api", "src/web")
+	s := &Session{Dir: home}
+
+	steps := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"pushd", []string{"src/api"}, "~/src/api ~"},
+		{"pushd", []string{"../web"}, "~/src/web ~/src/api ~"},
+		{"pushd", nil, "~/src/api ~/src/web ~"},
+		{"popd", nil, "~/src/web ~"},
+		{"popd", nil, "~"},
+	}
+	for _, step := range steps {
+		got, err := s.Run(step.name, step.args)
+		if err != nil {
+			t.Fatalf("%s %q: %v", step.name, step.args, err)
+		}
+		if got != step.want {
+			t.Errorf("%s %q printed %q, want %q", step.name, step.args, got, step.want)
+		}
+	}
+	if s.Dir != home {
+		t.Errorf("Dir = %q after popping everything, want %q", s.Dir, home)
+	}
+
+	if _, err := s.Run("popd", nil); err == nil {
+		t.Error("popd with an empty stack succeeded")
+	}
+	if _, err := s.Run("pushd", nil); err == nil {
+		t.Error("pushd without arguments succeeded with an empty stack")
+	}
+	if _, err := s.Run("pushd", []string{"nope"}); err == nil || s.Dir != home {
+		t.Errorf("pushd to a missing directory: error %v, Dir %q", err, s.Dir)
+	}
+	if _, err := s.Run("cd", []string{"a", "b"}); err == nil {
+		t.Error("cd with two arguments succeeded")
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := testHome(t)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"~", home},
+		{"~/src/api", filepath.Join(home, "src", "api")},
+		{"~/src/", filepath.Join(home, "src")},
+		{"~user/src", "~user/src"},
+		{"/etc/~", "/etc/~"},
+		{"src", "src"},
+	}
+	for _, tt := range tests {
+		if got := ExpandHome(tt.path); got != tt.want {
+			t.Errorf("ExpandHome(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAbbreviate(t *testing.T) {
+	home := testHome(t)
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{home, "~"},
+		{filepath.Join(home, "src"), filepath.Join("~", "src")},
+		{home + "2", home + "2"},
+		{"/etc", "/etc"},
+	}
+	for _, tt := range tests {
+		if got := Abbreviate(tt.dir); got != tt.want {
+			t.Errorf("Abbreviate(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestShort(t *testing.T) {
+	home := testHome(t)
+	dir := filepath.Join(home, "work", ".config", "infra", "prod")
+	tests := []struct {
+		max  int
+		want string
+	}{
+		{40, filepath.Join("~", "work", ".config", "infra", "prod")},
+		{10, filepath.Join("~", "w", ".c", "i", "prod")},
+	}
+	for _, tt := range tests {
+		if got := Short(dir, tt.max); got != tt.want {
+			t.Errorf("Short(%q, %d) = %q, want %q", dir, tt.max, got, tt.want)
+		}
+	}
+}
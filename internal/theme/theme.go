@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -49,6 +50,9 @@ type ThemeColors struct {
 // CurrentTheme is the active theme
 var CurrentTheme = DarkTheme()
 
+// CurrentThemePath is the JSON file the active theme was loaded from, if any
+var CurrentThemePath string
+
 // DarkTheme returns the default dark theme
 func DarkTheme() *Theme {
 	return &Theme{
@@ -221,6 +225,26 @@ func GetAvailableThemes() []string {
 // SetTheme sets the current theme by name
 func SetTheme(name string) {
 	CurrentTheme = GetTheme(name)
+	CurrentThemePath = ""
+}
+
+// SetThemeFile loads a custom theme from a JSON file and makes it current
+func SetThemeFile(path string) error {
+	t, err := LoadThemeFromFile(path)
+	if err != nil {
+		return err
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	CurrentTheme = t
+	CurrentThemePath = path
+	return nil
+}
+
+// IsThemeFile reports whether a --theme value refers to a JSON theme file rather than a built-in name
+func IsThemeFile(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".json")
 }
 
 // CycleTheme cycles to the next available theme and returns its name
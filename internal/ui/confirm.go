@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/duladissa/architerm/internal/commands"
+)
+
+// ConfirmDialog asks before running a destructive command
+type ConfirmDialog struct {
+	Command string
+	Danger  commands.Danger
+	Typed   string // Text typed so far for critical commands
+	Error   string
+	Width   int
+	styles  *Styles
+}
+
+// NewConfirmDialog creates a confirmation dialog for a command
+func NewConfirmDialog(styles *Styles, command string, danger commands.Danger) *ConfirmDialog {
+	return &ConfirmDialog{
+		Command: command,
+		Danger:  danger,
+		Width:   60,
+		styles:  styles,
+	}
+}
+
+// RequiresTyping returns true if the resource name must be typed to confirm
+func (d *ConfirmDialog) RequiresTyping() bool {
+	return d.Danger.Level == commands.DangerCritical
+}
+
+// InsertChar appends a character to the typed confirmation
+func (d *ConfirmDialog) InsertChar(ch rune) {
+	d.Typed += string(ch)
+	d.Error = ""
+}
+
+// DeleteChar removes the last typed character
+func (d *ConfirmDialog) DeleteChar() {
+	if len(d.Typed) > 0 {
+		d.Typed = d.Typed[:len(d.Typed)-1]
+		d.Error = ""
+	}
+}
+
+// Confirmed checks the typed text and sets an error if it does not match
+func (d *ConfirmDialog) Confirmed() bool {
+	if !d.RequiresTyping() || d.Typed == d.Danger.Confirm {
+		return true
+	}
+	d.Error = "does not match " + d.Danger.Confirm
+	return false
+}
+
+// SetWidth sets the dialog width
+func (d *ConfirmDialog) SetWidth(width int) {
+	d.Width = width
+}
+
+// SetStyles updates the styles for the dialog
+func (d *ConfirmDialog) SetStyles(styles *Styles) {
+	d.styles = styles
+}
+
+// View renders the dialog
+func (d *ConfirmDialog) View() string {
+	innerWidth := d.Width - 4
+	var lines []string
+
+	title := "⚠️  Confirm command"
+	if d.RequiresTyping() {
+		title = "🛑 Confirm destructive command"
+	}
+	lines = append(lines, d.styles.ModalError.Render(title))
+	lines = append(lines, d.styles.OutputPrompt.Render("$ ")+d.styles.OutputCommand.Render(truncateString(d.Command, innerWidth-2)))
+	lines = append(lines, d.styles.OutputSeparator.Render(strings.Repeat("─", innerWidth)))
+
+	if d.Danger.Reason != "" {
+		lines = append(lines, d.styles.ModalLabel.Render(truncateString("Reason: "+d.Danger.Reason, innerWidth)))
+	}
+
+	if d.RequiresTyping() {
+		lines = append(lines, d.styles.ModalLabel.Render("Type ")+d.styles.ModalLabelFocus.Render(d.Danger.Confirm)+d.styles.ModalLabel.Render(" to confirm:"))
+		lines = append(lines, d.styles.ModalInput.Render("  "+d.Typed)+d.styles.InputCursor.Render(" "))
+		if d.Error != "" {
+			lines = append(lines, d.styles.ModalError.Render("    ✗ "+d.Error))
+		}
+		lines = append(lines, "")
+		lines = append(lines, d.styles.ModalHint.Render("Enter: run │ Esc: cancel"))
+	} else {
+		lines = append(lines, "")
+		lines = append(lines, d.styles.ModalHint.Render("y: run │ n/Esc: cancel"))
+	}
+
+	return d.styles.ModalPanel.
+		Width(d.Width - 2).
+		Render(strings.Join(lines, "\n"))
+}
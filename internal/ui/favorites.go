@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/duladissa/architerm/internal/commands"
+)
+
+// FavoritesPanel shows one favorite set in place of the categories panel
+type FavoritesPanel struct {
+	Sets   []commands.FavoriteSet
+	Index  int // Set being shown
+	Width  int
+	Height int
+	styles *Styles
+}
+
+// NewFavoritesPanel creates a new favorites panel
+func NewFavoritesPanel(styles *Styles) *FavoritesPanel {
+	return &FavoritesPanel{
+		Width:  80,
+		Height: 3,
+		styles: styles,
+	}
+}
+
+// SetSets sets the favorite sets, keeping the shown set if it still exists
+func (p *FavoritesPanel) SetSets(sets []commands.FavoriteSet) {
+	p.Sets = sets
+	if p.Index >= len(sets) {
+		p.Index = 0
+	}
+}
+
+// Current returns the set being shown
+func (p *FavoritesPanel) Current() *commands.FavoriteSet {
+	if p.Index < 0 || p.Index >= len(p.Sets) {
+		return nil
+	}
+	return &p.Sets[p.Index]
+}
+
+// Command returns the n-th command (1-based) of the shown set, or ""
+func (p *FavoritesPanel) Command(n int) string {
+	set := p.Current()
+	if set == nil || n < 1 || n > len(set.Commands) {
+		return ""
+	}
+	return set.Commands[n-1]
+}
+
+// SetWidth sets the panel width
+func (p *FavoritesPanel) SetWidth(width int) {
+	p.Width = width
+}
+
+// SetHeight sets the panel height
+func (p *FavoritesPanel) SetHeight(height int) {
+	p.Height = height
+}
+
+// SetStyles updates the styles for the favorites panel
+func (p *FavoritesPanel) SetStyles(styles *Styles) {
+	p.styles = styles
+}
+
+// View renders the favorites panel
+func (p *FavoritesPanel) View() string {
+	innerWidth := p.Width - 6
+
+	title := "⭐ Favorites"
+	set := p.Current()
+	if set != nil && set.Name != commands.DefaultFavoriteSet {
+		title = "⭐ " + set.Name
+	}
+	if len(p.Sets) > 1 {
+		title += fmt.Sprintf(" (%d/%d)", p.Index+1, len(p.Sets))
+	}
+	titleText := p.styles.SuggestionsPanelTitle.Render(truncateString(title, innerWidth))
+
+	separatorWidth := innerWidth
+	if separatorWidth < 10 {
+		separatorWidth = 10
+	}
+	lines := []string{" " + strings.Repeat("─", separatorWidth)}
+
+	// Leave room for the title, separator, hint and borders
+	maxItems := p.Height - 4
+	if set == nil || len(set.Commands) == 0 {
+		lines = append(lines, p.styles.SuggestionDesc.Render("  No favorites yet. Press Ctrl+F on a suggestion to star it."))
+	} else {
+		for i, command := range set.Commands {
+			if i >= maxItems {
+				lines = append(lines, p.styles.SuggestionDesc.Render(fmt.Sprintf("  +%d more", len(set.Commands)-i)))
+				break
+			}
+			number := "  "
+			if i < 9 {
+				number = fmt.Sprintf("%d ", i+1)
+			}
+			lines = append(lines, " "+p.styles.SuggestionCategory.Render(number)+p.styles.SuggestionCommand.Render(truncateString(command, innerWidth-3)))
+		}
+	}
+
+	// Pad content to fill height, keeping the hint on the last line
+	for len(lines) < p.Height-3 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, p.styles.SuggestionDesc.Render(" Alt+1-9: insert │ Ctrl+G: next panel"))
+
+	return p.styles.SuggestionsPanel.
+		Width(p.Width - 2).
+		Height(p.Height).
+		Render(titleText + "\n" + strings.Join(lines, "\n"))
+}
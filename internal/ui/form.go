@@ -0,0 +1,382 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duladissa/architerm/internal/commands"
+)
+
+// maxVisibleCandidates is the number of provider candidates shown below a field
+const maxVisibleCandidates = 6
+
+// FormField is a single placeholder input in the placeholder form
+type FormField struct {
+	Placeholder commands.Placeholder
+	Value       string
+	Error       string
+
+	// Provider candidates
+	Candidates     []string
+	CandidatesKey  string // Resolved provider command the candidates belong to
+	CandidateErr   string
+	CandidateIndex int // Highlighted filtered candidate, -1 for none
+	Loading        bool
+	Edited         bool // Typed into since the value was last set, enables filtering
+}
+
+// PlaceholderForm is a modal form that collects placeholder values for a command
+type PlaceholderForm struct {
+	Title   string
+	Command string // Command text containing the placeholder tokens, previewed when set
+	Fields  []FormField
+	Focus   int
+	Width   int
+	styles  *Styles
+}
+
+// NewPlaceholderForm creates a form for the given command and its unresolved placeholders
+func NewPlaceholderForm(styles *Styles, command string, placeholders []commands.Placeholder) *PlaceholderForm {
+	fields := make([]FormField, len(placeholders))
+	for i, p := range placeholders {
+		fields[i] = FormField{
+			Placeholder:    p,
+			Value:          p.Default,
+			Candidates:     p.Values, // Allowed values double as picker candidates
+			CandidateIndex: -1,
+		}
+	}
+	return &PlaceholderForm{
+		Title:   "📝 Fill in placeholders",
+		Command: command,
+		Fields:  fields,
+		Focus:   0,
+		Width:   60,
+		styles:  styles,
+	}
+}
+
+// InsertChar appends a character to the focused field
+func (f *PlaceholderForm) InsertChar(ch rune) {
+	if field := f.focused(); field != nil {
+		field.Value += string(ch)
+		field.Error = ""
+		field.CandidateIndex = -1
+		field.Edited = true
+	}
+}
+
+// DeleteChar removes the last character of the focused field
+func (f *PlaceholderForm) DeleteChar() {
+	if field := f.focused(); field != nil && len(field.Value) > 0 {
+		field.Value = field.Value[:len(field.Value)-1]
+		field.Error = ""
+		field.CandidateIndex = -1
+		field.Edited = true
+	}
+}
+
+// ClearField clears the focused field
+func (f *PlaceholderForm) ClearField() {
+	if field := f.focused(); field != nil {
+		field.Value = ""
+		field.Error = ""
+		field.CandidateIndex = -1
+		field.Edited = true
+	}
+}
+
+// NextField validates the focused field and moves focus to the next one (wrapping around)
+func (f *PlaceholderForm) NextField() {
+	if len(f.Fields) > 0 {
+		f.checkField(f.Focus)
+		f.Focus = (f.Focus + 1) % len(f.Fields)
+	}
+}
+
+// PrevField validates the focused field and moves focus to the previous one (wrapping around)
+func (f *PlaceholderForm) PrevField() {
+	if len(f.Fields) > 0 {
+		f.checkField(f.Focus)
+		f.Focus = (f.Focus - 1 + len(f.Fields)) % len(f.Fields)
+	}
+}
+
+// checkField shows a validation error for a non-empty field being left,
+// so typos surface inline without nagging about fields not filled in yet
+func (f *PlaceholderForm) checkField(i int) {
+	field := &f.Fields[i]
+	if field.Value == "" {
+		return
+	}
+	if err := field.Placeholder.Validate(field.Value); err != nil {
+		field.Error = err.Error()
+	}
+}
+
+// IsLastField returns true if the last field has focus
+func (f *PlaceholderForm) IsLastField() bool {
+	return f.Focus >= len(f.Fields)-1
+}
+
+// ProviderCommand returns the resolved provider command of the focused field.
+// ok is false when the field has no provider or a placeholder it depends on is still empty.
+func (f *PlaceholderForm) ProviderCommand() (name, command string, ttl time.Duration, ok bool) {
+	field := f.focused()
+	if field == nil || field.Placeholder.Provider == nil {
+		return "", "", 0, false
+	}
+	provider := field.Placeholder.Provider
+
+	placeholders := make([]commands.Placeholder, 0, len(f.Fields))
+	for _, other := range f.Fields {
+		if other.Placeholder.Name != field.Placeholder.Name {
+			placeholders = append(placeholders, other.Placeholder)
+		}
+	}
+	values := f.Values()
+	deps := make(map[string]string)
+	for _, dep := range provider.Dependencies(placeholders) {
+		if strings.TrimSpace(values[dep]) == "" {
+			return "", "", 0, false
+		}
+		deps[dep] = values[dep]
+	}
+
+	return field.Placeholder.Name, commands.FillPlaceholders(provider.Command, deps), provider.CacheTTL(), true
+}
+
+// NeedsCandidates returns the provider command to run for the focused field, if its
+// candidates are missing or were produced for different dependency values
+func (f *PlaceholderForm) NeedsCandidates() (name, command string, ttl time.Duration, ok bool) {
+	name, command, ttl, ok = f.ProviderCommand()
+	if !ok {
+		return "", "", 0, false
+	}
+	field := f.focused()
+	if field.CandidatesKey == command {
+		return "", "", 0, false
+	}
+	field.CandidatesKey = command
+	field.Candidates = nil
+	field.CandidateErr = ""
+	field.CandidateIndex = -1
+	field.Loading = true
+	return name, command, ttl, true
+}
+
+// SetCandidates stores provider results for a field if they are still current
+func (f *PlaceholderForm) SetCandidates(name, key string, values []string, err error) {
+	for i := range f.Fields {
+		field := &f.Fields[i]
+		if field.Placeholder.Name != name || field.CandidatesKey != key {
+			continue
+		}
+		field.Loading = false
+		field.Candidates = values
+		field.CandidateErr = ""
+		if err != nil {
+			// Forget the key so the provider is retried when the field is revisited
+			field.CandidateErr = err.Error()
+			field.CandidatesKey = ""
+		}
+	}
+}
+
+// FilteredCandidates returns the focused field's candidates matching what was typed
+func (f *PlaceholderForm) FilteredCandidates() []string {
+	field := f.focused()
+	if field == nil {
+		return nil
+	}
+	if !field.Edited {
+		return field.Candidates
+	}
+	query := strings.ToLower(field.Value)
+	var matches []string
+	for _, c := range field.Candidates {
+		if strings.Contains(strings.ToLower(c), query) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// HasCandidates returns true if the focused field shows a candidate picker
+func (f *PlaceholderForm) HasCandidates() bool {
+	return len(f.FilteredCandidates()) > 0
+}
+
+// MoveCandidateDown highlights the next candidate
+func (f *PlaceholderForm) MoveCandidateDown() {
+	if field := f.focused(); field != nil && field.CandidateIndex < len(f.FilteredCandidates())-1 {
+		field.CandidateIndex++
+	}
+}
+
+// MoveCandidateUp highlights the previous candidate (or none)
+func (f *PlaceholderForm) MoveCandidateUp() {
+	if field := f.focused(); field != nil && field.CandidateIndex >= 0 {
+		field.CandidateIndex--
+	}
+}
+
+// AcceptCandidate copies the highlighted candidate into the field.
+// Returns false if no candidate is highlighted.
+func (f *PlaceholderForm) AcceptCandidate() bool {
+	field := f.focused()
+	if field == nil || field.CandidateIndex < 0 {
+		return false
+	}
+	candidates := f.FilteredCandidates()
+	if field.CandidateIndex >= len(candidates) {
+		return false
+	}
+	field.Value = candidates[field.CandidateIndex]
+	field.Error = ""
+	field.CandidateIndex = -1
+	field.Edited = false
+	return true
+}
+
+// Validate checks every field against its placeholder rules and focuses the first invalid one
+func (f *PlaceholderForm) Validate() bool {
+	valid := true
+	for i := range f.Fields {
+		field := &f.Fields[i]
+		field.Error = ""
+		if err := field.Placeholder.Validate(field.Value); err != nil {
+			field.Error = err.Error()
+			if valid {
+				f.Focus = i
+			}
+			valid = false
+		}
+	}
+	return valid
+}
+
+// Values returns the entered values keyed by placeholder name
+func (f *PlaceholderForm) Values() map[string]string {
+	values := make(map[string]string, len(f.Fields))
+	for _, field := range f.Fields {
+		values[field.Placeholder.Name] = field.Value
+	}
+	return values
+}
+
+// Resolve returns the command with all entered values filled in
+func (f *PlaceholderForm) Resolve() string {
+	return commands.FillPlaceholders(f.Command, f.Values())
+}
+
+// SetWidth sets the form width
+func (f *PlaceholderForm) SetWidth(width int) {
+	f.Width = width
+}
+
+// SetStyles updates the styles for the form
+func (f *PlaceholderForm) SetStyles(styles *Styles) {
+	f.styles = styles
+}
+
+// focused returns the focused field
+func (f *PlaceholderForm) focused() *FormField {
+	if f.Focus < 0 || f.Focus >= len(f.Fields) {
+		return nil
+	}
+	return &f.Fields[f.Focus]
+}
+
+// View renders the form
+func (f *PlaceholderForm) View() string {
+	innerWidth := f.Width - 4
+	var lines []string
+
+	lines = append(lines, f.styles.ModalTitle.Render(truncateString(f.Title, innerWidth)))
+	if f.Command != "" {
+		lines = append(lines, f.styles.OutputPrompt.Render("$ ")+f.styles.OutputCommand.Render(truncateString(f.Resolve(), innerWidth-2)))
+	}
+	lines = append(lines, f.styles.OutputSeparator.Render(strings.Repeat("─", innerWidth)))
+
+	for i, field := range f.Fields {
+		label := field.Placeholder.Name
+		if field.Placeholder.Required {
+			label += "*"
+		}
+		if t := field.Placeholder.Type; t != "" && t != commands.TypeString {
+			label += " (" + t + ")"
+		}
+
+		var labelText, valueText string
+		if i == f.Focus {
+			labelText = f.styles.ModalLabelFocus.Render(fmt.Sprintf("▶ %-22s", label))
+			valueText = f.styles.ModalInput.Render(field.Value) + f.styles.InputCursor.Render(" ")
+		} else {
+			labelText = f.styles.ModalLabel.Render(fmt.Sprintf("  %-22s", label))
+			valueText = f.styles.ModalInput.Render(field.Value)
+		}
+		lines = append(lines, labelText+" "+valueText)
+
+		if field.Error != "" {
+			lines = append(lines, f.styles.ModalError.Render("    ✗ "+field.Error))
+		} else if i == f.Focus && field.Placeholder.Description != "" {
+			lines = append(lines, f.styles.ModalHint.Render("    "+truncateString(field.Placeholder.Description, innerWidth-4)))
+		}
+
+		if i == f.Focus {
+			lines = append(lines, f.candidateLines(field, innerWidth)...)
+		}
+	}
+
+	lines = append(lines, "")
+	hint := "Tab/↑↓: move │ Enter: next/run │ Esc: cancel"
+	if f.HasCandidates() {
+		hint = "↑↓: pick │ Tab: move │ Enter: next/run │ Esc: cancel"
+	}
+	lines = append(lines, f.styles.ModalHint.Render(hint))
+
+	return f.styles.ModalPanel.
+		Width(f.Width - 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+// candidateLines renders the provider picker for a focused field
+func (f *PlaceholderForm) candidateLines(field FormField, width int) []string {
+	if field.Loading {
+		return []string{f.styles.ModalHint.Render("    ⏳ loading candidates...")}
+	}
+	if field.CandidateErr != "" {
+		return []string{f.styles.ModalError.Render("    " + truncateString(field.CandidateErr, width-4))}
+	}
+
+	candidates := f.FilteredCandidates()
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Keep the highlighted candidate visible
+	start := 0
+	if field.CandidateIndex >= maxVisibleCandidates {
+		start = field.CandidateIndex - maxVisibleCandidates + 1
+	}
+	end := start + maxVisibleCandidates
+	if end > len(candidates) {
+		end = len(candidates)
+	}
+
+	var lines []string
+	for i := start; i < end; i++ {
+		text := truncateString(candidates[i], width-8)
+		if i == field.CandidateIndex {
+			lines = append(lines, "    "+f.styles.SuggestionSelected.Render("▶ "+text))
+		} else {
+			lines = append(lines, f.styles.ModalLabel.Render("      "+text))
+		}
+	}
+	if len(candidates) > maxVisibleCandidates {
+		lines = append(lines, f.styles.ModalHint.Render(fmt.Sprintf("      [%d-%d of %d]", start+1, end, len(candidates))))
+	}
+	return lines
+}
@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/duladissa/architerm/internal/history"
+)
+
+// historySearchRows is the number of matches shown in the history search
+const historySearchRows = 10
+
+// HistorySearch is a modal for searching command history incrementally
+type HistorySearch struct {
+	Query         string
+	Items         []history.SearchResult
+	SelectedIndex int
+	Width         int
+	Now           time.Time // Reference time for entry ages
+	styles        *Styles
+}
+
+// NewHistorySearch creates a new history search overlay
+func NewHistorySearch(styles *Styles, query string) *HistorySearch {
+	return &HistorySearch{
+		Query:  query,
+		Width:  60,
+		Now:    time.Now(),
+		styles: styles,
+	}
+}
+
+// SetItems sets the matches and selects the best one
+func (s *HistorySearch) SetItems(items []history.SearchResult) {
+	s.Items = items
+	s.SelectedIndex = 0
+}
+
+// InsertChar appends a character to the query
+func (s *HistorySearch) InsertChar(ch rune) {
+	s.Query += string(ch)
+}
+
+// DeleteChar removes the last character of the query
+func (s *HistorySearch) DeleteChar() {
+	if runes := []rune(s.Query); len(runes) > 0 {
+		s.Query = string(runes[:len(runes)-1])
+	}
+}
+
+// MoveUp moves selection up
+func (s *HistorySearch) MoveUp() {
+	if s.SelectedIndex > 0 {
+		s.SelectedIndex--
+	}
+}
+
+// MoveDown moves selection down
+func (s *HistorySearch) MoveDown() {
+	if s.SelectedIndex < len(s.Items)-1 {
+		s.SelectedIndex++
+	}
+}
+
+// Next selects the next match, wrapping to the best one after the last
+func (s *HistorySearch) Next() {
+	if len(s.Items) > 0 {
+		s.SelectedIndex = (s.SelectedIndex + 1) % len(s.Items)
+	}
+}
+
+// GetSelected returns the selected match
+func (s *HistorySearch) GetSelected() *history.SearchResult {
+	if s.SelectedIndex < 0 || s.SelectedIndex >= len(s.Items) {
+		return nil
+	}
+	return &s.Items[s.SelectedIndex]
+}
+
+// SetWidth sets the overlay width
+func (s *HistorySearch) SetWidth(width int) {
+	s.Width = width
+}
+
+// SetStyles updates the styles for the overlay
+func (s *HistorySearch) SetStyles(styles *Styles) {
+	s.styles = styles
+}
+
+// View renders the overlay
+func (s *HistorySearch) View() string {
+	innerWidth := s.Width - 4
+	lines := []string{
+		s.styles.ModalTitle.Render("🔍 History search"),
+		s.styles.ModalLabelFocus.Render("search: ") + s.styles.ModalInput.Render(s.Query) + s.styles.InputCursor.Render(" "),
+		s.styles.OutputSeparator.Render(strings.Repeat("─", innerWidth)),
+	}
+
+	if len(s.Items) == 0 {
+		lines = append(lines, s.styles.ModalHint.Render("  No matching commands"))
+	}
+
+	// Keep the selection in view
+	start := 0
+	if s.SelectedIndex >= historySearchRows {
+		start = s.SelectedIndex - historySearchRows + 1
+	}
+	end := start + historySearchRows
+	if end > len(s.Items) {
+		end = len(s.Items)
+	}
+
+	for i := start; i < end; i++ {
+		lines = append(lines, s.renderItem(s.Items[i], i == s.SelectedIndex, innerWidth))
+	}
+	if len(s.Items) > historySearchRows {
+		lines = append(lines, s.styles.ModalHint.Render(fmt.Sprintf("  [%d-%d of %d]", start+1, end, len(s.Items))))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, s.styles.ModalHint.Render("Ctrl+R/↑↓: select │ Enter: edit │ Esc: cancel"))
+
+	return s.styles.ModalPanel.
+		Width(s.Width - 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+// renderItem renders one match: exit status, command with highlighted matches, and age
+func (s *HistorySearch) renderItem(item history.SearchResult, selected bool, width int) string {
+	status := s.styles.OutputExitOK.Render("✓")
+	if item.Entry.ExitCode != 0 {
+		status = s.styles.OutputExitFail.Render("✗")
+	}
+	age := FormatAge(s.Now.Sub(item.Entry.Time))
+
+	marker := "  "
+	textStyle := s.styles.ModalLabel
+	if selected {
+		marker = "▶ "
+		textStyle = s.styles.ModalLabelFocus
+	}
+
+	// marker + status + space + command + space + age
+	commandWidth := width - 4 - 1 - len(age)
+	command := []rune(item.Entry.Command)
+	truncated := false
+	if commandWidth > 0 && len(command) > commandWidth {
+		command = command[:commandWidth-1]
+		truncated = true
+	}
+
+	matched := make(map[int]bool, len(item.Positions))
+	for _, p := range item.Positions {
+		matched[p] = true
+	}
+	var sb strings.Builder
+	for i, r := range command {
+		if matched[i] {
+			sb.WriteString(s.styles.SearchMatch.Render(string(r)))
+		} else {
+			sb.WriteString(textStyle.Render(string(r)))
+		}
+	}
+	if truncated {
+		sb.WriteString(textStyle.Render("…"))
+	}
+
+	line := textStyle.Render(marker) + status + textStyle.Render(" ") + sb.String()
+	gap := width - lipgloss.Width(line) - len(age)
+	if gap < 1 {
+		gap = 1
+	}
+	return line + textStyle.Render(strings.Repeat(" ", gap)) + s.styles.ModalHint.Render(age)
+}
+
+// FormatAge formats how long ago something happened, e.g. "5m" or "3d"
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dw", int(d.Hours()/(24*7)))
+	default:
+		return fmt.Sprintf("%dy", int(d.Hours()/(24*365)))
+	}
+}
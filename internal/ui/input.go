@@ -4,6 +4,8 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/session"
 )
 
 // InputPanel represents the command input area
@@ -13,7 +15,12 @@ type InputPanel struct {
 	GhostText string
 	Width     int
 	Focused   bool
+	Dir       string // Working directory shown in the prompt
 	styles    *Styles
+
+	// Tab stops: placeholder tokens of the accepted template and the selected one
+	Placeholders []string
+	Selection    commands.Span // Empty (Start == End) when nothing is selected
 }
 
 // NewInputPanel creates a new input panel
@@ -32,10 +39,89 @@ func NewInputPanel(styles *Styles) *InputPanel {
 func (p *InputPanel) SetValue(value string) {
 	p.Value = value
 	p.CursorPos = len(value)
+	p.ClearSelection()
+}
+
+// SetPlaceholders sets the placeholder names treated as tab stops
+func (p *InputPanel) SetPlaceholders(names []string) {
+	p.Placeholders = names
 }
 
-// InsertChar inserts a character at cursor position
+// HasPlaceholders returns true if any tab-stop placeholder is left in the input
+func (p *InputPanel) HasPlaceholders() bool {
+	return len(commands.FindPlaceholderSpans(p.Value, p.Placeholders)) > 0
+}
+
+// HasSelection returns true if a placeholder is selected
+func (p *InputPanel) HasSelection() bool {
+	return p.Selection.End > p.Selection.Start
+}
+
+// ClearSelection drops the current selection, keeping the cursor in place
+func (p *InputPanel) ClearSelection() {
+	p.Selection = commands.Span{}
+}
+
+// SelectNextPlaceholder selects the first placeholder after the cursor, wrapping
+// around to the start. Returns false if no placeholder is left.
+func (p *InputPanel) SelectNextPlaceholder() bool {
+	spans := commands.FindPlaceholderSpans(p.Value, p.Placeholders)
+	if len(spans) == 0 {
+		return false
+	}
+	from := p.CursorPos
+	if p.HasSelection() {
+		from = p.Selection.End
+	}
+	target := spans[0]
+	for _, span := range spans {
+		if span.Start >= from {
+			target = span
+			break
+		}
+	}
+	p.selectSpan(target)
+	return true
+}
+
+// SelectPrevPlaceholder selects the last placeholder before the cursor, wrapping
+// around to the end. Returns false if no placeholder is left.
+func (p *InputPanel) SelectPrevPlaceholder() bool {
+	spans := commands.FindPlaceholderSpans(p.Value, p.Placeholders)
+	if len(spans) == 0 {
+		return false
+	}
+	target := spans[len(spans)-1]
+	for i := len(spans) - 1; i >= 0; i-- {
+		if spans[i].End <= p.CursorPos && spans[i].Start < p.CursorPos {
+			target = spans[i]
+			break
+		}
+	}
+	p.selectSpan(target)
+	return true
+}
+
+// selectSpan selects a range and puts the cursor at its start
+func (p *InputPanel) selectSpan(span commands.Span) {
+	p.Selection = span
+	p.CursorPos = span.Start
+}
+
+// deleteSelection removes the selected text. Returns false if nothing was selected.
+func (p *InputPanel) deleteSelection() bool {
+	if !p.HasSelection() {
+		return false
+	}
+	p.Value = p.Value[:p.Selection.Start] + p.Value[p.Selection.End:]
+	p.CursorPos = p.Selection.Start
+	p.ClearSelection()
+	return true
+}
+
+// InsertChar inserts a character at cursor position, replacing any selection
 func (p *InputPanel) InsertChar(ch rune) {
+	p.deleteSelection()
 	if p.CursorPos >= len(p.Value) {
 		p.Value += string(ch)
 	} else {
@@ -44,16 +130,22 @@ func (p *InputPanel) InsertChar(ch rune) {
 	p.CursorPos++
 }
 
-// DeleteChar deletes character before cursor (backspace)
+// DeleteChar deletes character before cursor (backspace), or the selection
 func (p *InputPanel) DeleteChar() {
+	if p.deleteSelection() {
+		return
+	}
 	if p.CursorPos > 0 && len(p.Value) > 0 {
 		p.Value = p.Value[:p.CursorPos-1] + p.Value[p.CursorPos:]
 		p.CursorPos--
 	}
 }
 
-// DeleteCharForward deletes character at cursor (delete key)
+// DeleteCharForward deletes character at cursor (delete key), or the selection
 func (p *InputPanel) DeleteCharForward() {
+	if p.deleteSelection() {
+		return
+	}
 	if p.CursorPos < len(p.Value) {
 		p.Value = p.Value[:p.CursorPos] + p.Value[p.CursorPos+1:]
 	}
@@ -61,6 +153,7 @@ func (p *InputPanel) DeleteCharForward() {
 
 // MoveCursorLeft moves cursor left
 func (p *InputPanel) MoveCursorLeft() {
+	p.ClearSelection()
 	if p.CursorPos > 0 {
 		p.CursorPos--
 	}
@@ -68,6 +161,11 @@ func (p *InputPanel) MoveCursorLeft() {
 
 // MoveCursorRight moves cursor right
 func (p *InputPanel) MoveCursorRight() {
+	if p.HasSelection() {
+		p.CursorPos = p.Selection.End
+		p.ClearSelection()
+		return
+	}
 	if p.CursorPos < len(p.Value) {
 		p.CursorPos++
 	}
@@ -75,11 +173,13 @@ func (p *InputPanel) MoveCursorRight() {
 
 // MoveCursorStart moves cursor to start
 func (p *InputPanel) MoveCursorStart() {
+	p.ClearSelection()
 	p.CursorPos = 0
 }
 
 // MoveCursorEnd moves cursor to end
 func (p *InputPanel) MoveCursorEnd() {
+	p.ClearSelection()
 	p.CursorPos = len(p.Value)
 }
 
@@ -88,6 +188,8 @@ func (p *InputPanel) Clear() {
 	p.Value = ""
 	p.CursorPos = 0
 	p.GhostText = ""
+	p.Placeholders = nil
+	p.ClearSelection()
 }
 
 // AcceptGhostText accepts the ghost text completion
@@ -118,9 +220,17 @@ func (p *InputPanel) SetStyles(styles *Styles) {
 func (p *InputPanel) View() string {
 	// Build input line with cursor
 	prompt := p.styles.InputPrompt.Render("> ")
+	if p.Dir != "" {
+		prompt = p.styles.InputGhost.Render(session.Short(p.Dir, p.Width/3)+" ") + prompt
+	}
 	
 	var inputLine string
-	if p.Focused {
+	if p.Focused && (p.HasSelection() || len(p.Placeholders) > 0) {
+		inputLine = p.renderWithPlaceholders()
+		if p.CursorPos == len(p.Value) {
+			inputLine += p.styles.InputCursor.Render(" ")
+		}
+	} else if p.Focused {
 		// Show cursor
 		beforeCursor := p.Value[:p.CursorPos]
 		afterCursor := ""
@@ -163,3 +273,41 @@ func (p *InputPanel) View() string {
 
 	return panel
 }
+
+// renderWithPlaceholders renders the value with placeholder tokens, the
+// selected tab stop and the cursor styled distinctly
+func (p *InputPanel) renderWithPlaceholders() string {
+	spans := commands.FindPlaceholderSpans(p.Value, p.Placeholders)
+
+	// Classify each byte: 0 text, 1 placeholder, 2 selection, 3 cursor
+	classAt := func(i int) int {
+		if p.HasSelection() && i >= p.Selection.Start && i < p.Selection.End {
+			return 2
+		}
+		if !p.HasSelection() && i == p.CursorPos {
+			return 3
+		}
+		for _, span := range spans {
+			if i >= span.Start && i < span.End {
+				return 1
+			}
+		}
+		return 0
+	}
+	classStyles := []lipgloss.Style{
+		p.styles.InputText,
+		p.styles.InputPlaceholder,
+		p.styles.InputSelection,
+		p.styles.InputCursor,
+	}
+
+	var sb strings.Builder
+	start := 0
+	for i := 1; i <= len(p.Value); i++ {
+		if i == len(p.Value) || classAt(i) != classAt(start) {
+			sb.WriteString(classStyles[classAt(start)].Render(p.Value[start:i]))
+			start = i
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/duladissa/architerm/internal/jobs"
+)
+
+// JobsPanel lists background jobs in place of the categories panel
+type JobsPanel struct {
+	Jobs          []*jobs.Job
+	SelectedIndex int
+	Focused       bool // Keys go to the panel (Ctrl+K)
+	Shown         int  // ID of the job shown in the output panel
+	Now           time.Time
+	Width         int
+	Height        int
+	styles        *Styles
+}
+
+// NewJobsPanel creates a new jobs panel
+func NewJobsPanel(styles *Styles) *JobsPanel {
+	return &JobsPanel{
+		Width:  80,
+		Height: 3,
+		styles: styles,
+	}
+}
+
+// SetJobs sets the jobs to list, keeping the selection in range
+func (p *JobsPanel) SetJobs(list []*jobs.Job) {
+	p.Jobs = list
+	if p.SelectedIndex >= len(list) {
+		p.SelectedIndex = len(list) - 1
+	}
+	if p.SelectedIndex < 0 {
+		p.SelectedIndex = 0
+	}
+}
+
+// MoveUp moves selection up
+func (p *JobsPanel) MoveUp() {
+	if p.SelectedIndex > 0 {
+		p.SelectedIndex--
+	}
+}
+
+// MoveDown moves selection down
+func (p *JobsPanel) MoveDown() {
+	if p.SelectedIndex < len(p.Jobs)-1 {
+		p.SelectedIndex++
+	}
+}
+
+// Select selects the job with the given ID
+func (p *JobsPanel) Select(id int) {
+	for i, job := range p.Jobs {
+		if job.ID == id {
+			p.SelectedIndex = i
+			return
+		}
+	}
+}
+
+// GetSelected returns the selected job
+func (p *JobsPanel) GetSelected() *jobs.Job {
+	if p.SelectedIndex < 0 || p.SelectedIndex >= len(p.Jobs) {
+		return nil
+	}
+	return p.Jobs[p.SelectedIndex]
+}
+
+// SetWidth sets the panel width
+func (p *JobsPanel) SetWidth(width int) {
+	p.Width = width
+}
+
+// SetHeight sets the panel height
+func (p *JobsPanel) SetHeight(height int) {
+	p.Height = height
+}
+
+// SetStyles updates the styles for the jobs panel
+func (p *JobsPanel) SetStyles(styles *Styles) {
+	p.styles = styles
+}
+
+// View renders the jobs panel
+func (p *JobsPanel) View() string {
+	innerWidth := p.Width - 6
+
+	running := 0
+	for _, job := range p.Jobs {
+		if job.Running() {
+			running++
+		}
+	}
+	title := "⚙️  Jobs"
+	if len(p.Jobs) > 0 {
+		title += fmt.Sprintf(" (%d running)", running)
+	}
+	titleText := p.styles.SuggestionsPanelTitle.Render(truncateString(title, innerWidth))
+
+	// Each job takes two lines; leave room for the hint and borders
+	maxJobs := (p.Height - 4) / 2
+	if maxJobs < 1 {
+		maxJobs = 1
+	}
+	start := 0
+	if p.SelectedIndex >= maxJobs {
+		start = p.SelectedIndex - maxJobs + 1
+	}
+
+	var lines []string
+	if len(p.Jobs) == 0 {
+		lines = append(lines, p.styles.SuggestionDesc.Render("  No jobs. Commands you run are listed here."))
+	}
+	for i := start; i < len(p.Jobs) && i < start+maxJobs; i++ {
+		job := p.Jobs[i]
+		marker := "  "
+		if i == p.SelectedIndex && p.Focused {
+			marker = "▶ "
+		} else if job.ID == p.Shown {
+			marker = "› "
+		}
+		head := fmt.Sprintf("%s[%d] %s %s  ", marker, job.ID, jobIcon(job.Status()), FormatElapsed(job.Elapsed(p.Now)))
+		text := head + truncateString(job.Command, innerWidth-len([]rune(head)))
+		if i == p.SelectedIndex && p.Focused {
+			lines = append(lines, p.styles.SuggestionSelected.Render(text))
+		} else {
+			lines = append(lines, p.jobStyle(job.Status()).Render(text))
+		}
+		lines = append(lines, p.styles.SuggestionDesc.Render(truncateString("      "+job.LastLine(), innerWidth)))
+	}
+
+	// Keep the hint line at the bottom of the panel
+	for len(lines) < p.Height-4 {
+		lines = append(lines, "")
+	}
+	hint := " Ctrl+K: manage │ Ctrl+G: next panel"
+	if p.Focused {
+		hint = " ↑↓ │ Enter: show │ x: cancel │ d: remove │ Esc"
+	}
+	lines = append(lines, p.styles.StatusKeyHint.Render(truncateString(hint, innerWidth)))
+
+	return p.styles.SuggestionsPanel.
+		Width(p.Width - 2).
+		Height(p.Height).
+		Render(titleText + "\n" + strings.Join(lines, "\n"))
+}
+
+// jobStyle returns the text style for a job status
+func (p *JobsPanel) jobStyle(status string) lipgloss.Style {
+	switch status {
+	case jobs.StatusRunning:
+		return p.styles.OutputCommand
+	case jobs.StatusDone:
+		return p.styles.OutputExitOK
+	case jobs.StatusFailed, jobs.StatusTimedOut:
+		return p.styles.OutputExitFail
+	default:
+		return p.styles.SuggestionDesc
+	}
+}
+
+// jobIcon returns the status icon for a job
+func jobIcon(status string) string {
+	switch status {
+	case jobs.StatusRunning:
+		return "⏳"
+	case jobs.StatusDone:
+		return "✓"
+	case jobs.StatusFailed:
+		return "✗"
+	case jobs.StatusTimedOut:
+		return "⏱"
+	default:
+		return "⊘"
+	}
+}
+
+// FormatElapsed formats a duration compactly, like 42s, 3m05s or 1h02m
+func FormatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
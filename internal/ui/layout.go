@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/duladissa/architerm/internal/theme"
 )
 
 // Layout manages the three-panel layout (side-by-side)
@@ -116,11 +117,15 @@ func (l *Layout) RenderStatusBar(status string) string {
 		l.styles.StatusKeyHint.Render("Ctrl+C") + " exit",
 	}
 	
-	hintsStr := strings.Join(keyHints, " │ ")
-	
 	if status != "" {
 		status = " " + status + " │ "
 	}
+
+	// Drop hints from the end rather than wrapping onto a second line
+	for len(keyHints) > 1 && lipgloss.Width(status+strings.Join(keyHints, " │ ")) > l.Width-2 {
+		keyHints = keyHints[:len(keyHints)-1]
+	}
+	hintsStr := strings.Join(keyHints, " │ ")
 	
 	return statusStyle.Render(status + hintsStr)
 }
@@ -136,3 +141,26 @@ func (l *Layout) Render(header, input, suggestions, categories, output, statusBa
 	// Combine header, main content, and status bar
 	return lipgloss.JoinVertical(lipgloss.Left, header, mainContent, statusBar)
 }
+
+// ModalWidth returns a comfortable width for modal dialogs
+func (l *Layout) ModalWidth() int {
+	w := l.Width - 10
+	if w > 80 {
+		w = 80
+	}
+	if w < 40 {
+		w = 40
+	}
+	return w
+}
+
+// RenderModal renders a modal centered over the main content area
+func (l *Layout) RenderModal(header, modal, statusBar string) string {
+	height := l.Height - lipgloss.Height(header) - lipgloss.Height(statusBar)
+	if height < lipgloss.Height(modal) {
+		height = lipgloss.Height(modal)
+	}
+	body := lipgloss.Place(l.Width, height, lipgloss.Center, lipgloss.Center, modal,
+		lipgloss.WithWhitespaceBackground(theme.CurrentTheme.GetBackground()))
+	return lipgloss.JoinVertical(lipgloss.Left, header, body, statusBar)
+}
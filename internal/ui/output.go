@@ -4,21 +4,31 @@ import (
 	"strings"
 	"time"
 
+	"github.com/duladissa/architerm/internal/executor"
 	"github.com/duladissa/architerm/internal/theme"
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// TerminalScreen is a pseudo-terminal drawn in the output panel
+type TerminalScreen interface {
+	// Lines renders the screen rows, showing the cursor if cursor is set
+	Lines(cursor bool) []string
+}
+
 // OutputEntry represents a single command output entry
 type OutputEntry struct {
 	Command  string
 	Output   string
+	Stdout   string
+	Stderr   string
+	Dir      string // Working directory the command ran in
 	FullText string // Complete formatted output for copying
 }
 
 // OutputPanel represents the command output area
 type OutputPanel struct {
-	Content       string
+	Content       string // Joined text of the chunks; not kept up to date while live
 	Lines         []string
 	Entries       []OutputEntry // Individual command outputs
 	SelectedEntry int           // Currently selected entry for copying
@@ -27,6 +37,21 @@ type OutputPanel struct {
 	Height        int
 	styles        *Styles
 	CopyMessage   string // Temporary message shown after copy
+
+	// chunks is the content tagged by stream; stderrLines marks the Lines
+	// the command wrote to stderr
+	chunks      []executor.Chunk
+	stderrLines []bool
+	size        int // Bytes of text in chunks
+
+	// Live output of a running command
+	Live   bool // A command is streaming output into the panel
+	Follow bool // Keep the newest output in view; paused by scrolling up
+
+	// Terminal is a command running in a pseudo-terminal, shown instead of the content
+	Terminal        TerminalScreen
+	TerminalTitle   string
+	TerminalFocused bool // Key presses go to the terminal
 	
 	// Mouse selection state
 	IsSelecting     bool
@@ -53,23 +78,69 @@ func NewOutputPanel(styles *Styles) *OutputPanel {
 
 // SetContent sets the output content
 func (p *OutputPanel) SetContent(content string) {
-	p.Content = content
-	p.Lines = strings.Split(content, "\n")
+	p.setChunks([]executor.Chunk{{Text: content}})
 	// Auto-scroll to bottom
 	p.ScrollToBottom()
 }
 
 // AppendContent appends content to the output
 func (p *OutputPanel) AppendContent(content string) {
-	if p.Content == "" {
-		p.Content = content
-	} else {
-		p.Content += content
-	}
-	p.Lines = strings.Split(p.Content, "\n")
+	p.setChunks(append(p.chunks, executor.Chunk{Text: content}))
 	p.ScrollToBottom()
 }
 
+// setChunks replaces the content, marking the lines written to stderr
+func (p *OutputPanel) setChunks(chunks []executor.Chunk) {
+	p.chunks = chunks
+	p.Content = executor.JoinChunks(chunks, true, true)
+	p.size = len(p.Content)
+	p.Lines = strings.Split(p.Content, "\n")
+
+	// A line is stderr if any of its text came from stderr
+	p.stderrLines = make([]bool, len(p.Lines))
+	line := 0
+	for _, chunk := range chunks {
+		parts := strings.Split(chunk.Text, "\n")
+		for i, part := range parts {
+			if chunk.Stderr && part != "" {
+				p.stderrLines[line] = true
+			}
+			if i < len(parts)-1 {
+				line++
+			}
+		}
+	}
+}
+
+// appendChunk adds a chunk to the content, splitting only its own text into lines
+func (p *OutputPanel) appendChunk(chunk executor.Chunk) {
+	p.chunks = append(p.chunks, chunk)
+	p.size += len(chunk.Text)
+	if len(p.Lines) == 0 {
+		p.Lines = []string{""}
+		p.stderrLines = []bool{false}
+	}
+
+	parts := strings.Split(chunk.Text, "\n")
+	last := len(p.Lines) - 1
+	p.Lines[last] += parts[0]
+	if chunk.Stderr && parts[0] != "" {
+		p.stderrLines[last] = true
+	}
+	for _, part := range parts[1:] {
+		p.Lines = append(p.Lines, part)
+		p.stderrLines = append(p.stderrLines, chunk.Stderr && part != "")
+	}
+}
+
+// trimLive drops the oldest output once more than executor.MaxOutput is
+// shown, keeping the header and the newest half like a job's own buffer
+func (p *OutputPanel) trimLive() {
+	if chunks, _, dropped := executor.TrimChunks(p.chunks, p.size, 1); dropped {
+		p.setChunks(chunks)
+	}
+}
+
 // AddEntry adds a new command output entry (replaces previous output display)
 func (p *OutputPanel) AddEntry(command, output, fullText string) {
 	entry := OutputEntry{
@@ -82,10 +153,88 @@ func (p *OutputPanel) AddEntry(command, output, fullText string) {
 	p.SelectedEntry = len(p.Entries) - 1 // Select the latest entry
 	
 	// Replace content with only the latest output
-	p.Content = fullText
-	p.Lines = strings.Split(p.Content, "\n")
+	p.setChunks([]executor.Chunk{{Text: fullText}})
 	p.ScrollOffset = 0 // Reset scroll to top for new output
 	p.CopyMessage = "" // Clear any previous copy message
+	p.Live = false
+}
+
+// AddResult adds the output entry of a finished command, shown below prefix
+// with its stderr lines marked
+func (p *OutputPanel) AddResult(prefix string, result *executor.Result) {
+	chunks := append([]executor.Chunk{{Text: prefix}}, executor.FormatResultChunks(result)...)
+	p.AddEntry(result.Command, result.Output, executor.JoinChunks(chunks, true, true))
+	entry := &p.Entries[len(p.Entries)-1]
+	entry.Stdout = result.Stdout()
+	entry.Stderr = result.Stderr()
+	entry.Dir = result.Dir
+	p.setChunks(chunks)
+}
+
+// StartLive replaces the content with header and the output a running
+// command has written so far, and starts following its output
+func (p *OutputPanel) StartLive(header string, output []executor.Chunk) {
+	p.Live = true
+	p.Follow = true
+	p.setChunks(append([]executor.Chunk{{Text: header}}, output...))
+	p.CopyMessage = ""
+	p.ClearSelection()
+	p.ScrollToBottom()
+}
+
+// AppendLive adds output of the running command, keeping it in view unless
+// the user has scrolled up
+func (p *OutputPanel) AppendLive(chunk executor.Chunk) {
+	if !p.Live {
+		return
+	}
+	p.appendChunk(chunk)
+	p.trimLive()
+	if p.Follow {
+		p.ScrollToBottom()
+	} else if maxOffset := p.maxOffset(); p.ScrollOffset > maxOffset {
+		p.ScrollOffset = maxOffset
+	}
+}
+
+// FinishLive records the finished command like AddResult, staying at the
+// bottom if following or at the user's scroll position otherwise
+func (p *OutputPanel) FinishLive(prefix string, result *executor.Result) {
+	if !p.Live {
+		p.AddResult(prefix, result)
+		return
+	}
+	offset := p.ScrollOffset
+	p.AddResult(prefix, result)
+	if p.Follow {
+		p.ScrollToBottom()
+		return
+	}
+	p.ScrollOffset = offset
+	if maxOffset := p.maxOffset(); p.ScrollOffset > maxOffset {
+		p.ScrollOffset = maxOffset
+	}
+}
+
+// ShowTerminal shows a pseudo-terminal in place of the content
+func (p *OutputPanel) ShowTerminal(title string, screen TerminalScreen) {
+	p.Terminal = screen
+	p.TerminalTitle = title
+	p.TerminalFocused = true
+	p.CopyMessage = ""
+	p.ClearSelection()
+}
+
+// HideTerminal goes back to showing the content
+func (p *OutputPanel) HideTerminal() {
+	p.Terminal = nil
+	p.TerminalTitle = ""
+	p.TerminalFocused = false
+}
+
+// TerminalSize returns the columns and rows available to a pseudo-terminal
+func (p *OutputPanel) TerminalSize() (cols, rows int) {
+	return p.Width - 4, p.contentLines()
 }
 
 // GetEntryCount returns the number of entries
@@ -139,6 +288,29 @@ func (p *OutputPanel) CopyLastOutput() error {
 	return nil
 }
 
+// CopyLastStream copies what the last command wrote to stdout, or to stderr
+// if stderr is set
+func (p *OutputPanel) CopyLastStream(stderr bool) error {
+	if len(p.Entries) == 0 {
+		return nil
+	}
+	entry := p.Entries[len(p.Entries)-1]
+	text, name := entry.Stdout, "Stdout"
+	if stderr {
+		text, name = entry.Stderr, "Stderr"
+	}
+	if text == "" {
+		p.CopyMessage = "⚠️  No " + strings.ToLower(name) + " to copy"
+		return nil
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		p.CopyMessage = "❌ Copy failed!"
+		return err
+	}
+	p.CopyMessage = "✅ " + name + " copied!"
+	return nil
+}
+
 // CopyLastCommand copies the last command to clipboard
 func (p *OutputPanel) CopyLastCommand() error {
 	if len(p.Entries) == 0 {
@@ -270,37 +442,47 @@ func (p *OutputPanel) IsLineSelected(lineIndex int) bool {
 func (p *OutputPanel) Clear() {
 	p.Content = ""
 	p.Lines = make([]string, 0)
+	p.chunks = nil
+	p.stderrLines = nil
+	p.size = 0
 	p.Entries = make([]OutputEntry, 0)
 	p.SelectedEntry = -1
 	p.ScrollOffset = 0
 	p.CopyMessage = ""
 }
 
-// ScrollUp scrolls the output up
+// ScrollUp scrolls the output up, pausing auto-follow
 func (p *OutputPanel) ScrollUp() {
 	if p.ScrollOffset > 0 {
 		p.ScrollOffset--
+		p.Follow = false
 	}
 }
 
-// ScrollDown scrolls the output down
+// ScrollDown scrolls the output down, resuming auto-follow at the bottom
 func (p *OutputPanel) ScrollDown() {
-	maxOffset := len(p.Lines) - p.visibleLines()
-	if maxOffset < 0 {
-		maxOffset = 0
-	}
+	maxOffset := p.maxOffset()
 	if p.ScrollOffset < maxOffset {
 		p.ScrollOffset++
 	}
+	if p.ScrollOffset >= maxOffset {
+		p.Follow = true
+	}
 }
 
-// ScrollToBottom scrolls to the bottom
+// ScrollToBottom scrolls to the bottom and resumes auto-follow
 func (p *OutputPanel) ScrollToBottom() {
-	maxOffset := len(p.Lines) - p.visibleLines()
+	p.ScrollOffset = p.maxOffset()
+	p.Follow = true
+}
+
+// maxOffset returns the scroll offset showing the last lines
+func (p *OutputPanel) maxOffset() int {
+	maxOffset := len(p.Lines) - p.contentLines()
 	if maxOffset < 0 {
 		maxOffset = 0
 	}
-	p.ScrollOffset = maxOffset
+	return maxOffset
 }
 
 // ScrollToTop scrolls to the top
@@ -313,6 +495,14 @@ func (p *OutputPanel) visibleLines() int {
 	return p.Height - 2 // Account for borders
 }
 
+// contentLines returns the number of output lines shown below the title
+func (p *OutputPanel) contentLines() int {
+	if count := p.visibleLines() - 1; count > 1 {
+		return count
+	}
+	return 1
+}
+
 // SetWidth sets the panel width
 func (p *OutputPanel) SetWidth(width int) {
 	p.Width = width
@@ -336,22 +526,38 @@ func (p *OutputPanel) View() string {
 		titleParts = append(titleParts, lipgloss.NewStyle().Foreground(theme.CurrentTheme.GetMuted()).Render(
 			" │ Ctrl+Y: copy output │ Ctrl+B: copy cmd"))
 	}
+	if p.Live {
+		if p.Follow {
+			titleParts = append(titleParts, p.styles.OutputExitOK.Render(" ● live"))
+		} else {
+			titleParts = append(titleParts, lipgloss.NewStyle().Foreground(theme.CurrentTheme.GetWarning()).Render(" ⏸ paused: scroll down to follow"))
+		}
+	}
 	if p.CopyMessage != "" {
 		titleParts = append(titleParts, " "+p.CopyMessage)
 	}
 	titleText := p.styles.OutputPanelTitle.Render(strings.Join(titleParts, ""))
 
-	visibleCount := p.visibleLines() - 1 // Account for title
-	if visibleCount < 1 {
-		visibleCount = 1
-	}
+	visibleCount := p.contentLines()
 	var lines []string
 
-	if len(p.Lines) == 0 {
+	if p.Terminal != nil {
+		hint := " │ Alt+Enter: focus │ Ctrl+C: kill"
+		if p.TerminalFocused {
+			hint = " │ keys go to the command │ Ctrl+]: release"
+		}
+		titleText = p.styles.OutputPanelTitle.Render("🖥️  "+truncateString(p.TerminalTitle, p.Width/2)) +
+			lipgloss.NewStyle().Foreground(theme.CurrentTheme.GetMuted()).Render(hint)
+		lines = p.Terminal.Lines(p.TerminalFocused)
+		if len(lines) > visibleCount {
+			lines = lines[:visibleCount]
+		}
+	} else if len(p.Lines) == 0 {
 		lines = append(lines, p.styles.SuggestionDesc.Render("  Press Enter to execute a command..."))
 		lines = append(lines, "")
 		lines = append(lines, p.styles.SuggestionDesc.Render("  Copy shortcuts:"))
 		lines = append(lines, p.styles.SuggestionDesc.Render("  • Ctrl+Y - Copy last output"))
+		lines = append(lines, p.styles.SuggestionDesc.Render("  • Alt+O / Alt+E - Copy last stdout / stderr"))
 		lines = append(lines, p.styles.SuggestionDesc.Render("  • Ctrl+B - Copy last command"))
 	} else {
 		endIndex := p.ScrollOffset + visibleCount
@@ -367,7 +573,7 @@ func (p *OutputPanel) View() string {
 			}
 			
 			// Apply Unix/Linux style coloring based on line type
-			styledLine := p.styleLine(line)
+			styledLine := p.styleLine(line, i < len(p.stderrLines) && p.stderrLines[i])
 			
 			// Highlight selected lines
 			if p.IsLineSelected(i) {
@@ -410,7 +616,8 @@ func (p *OutputPanel) View() string {
 }
 
 // styleLine applies Unix/Linux style coloring to a line based on its content
-func (p *OutputPanel) styleLine(line string) string {
+// and whether the command wrote it to stderr
+func (p *OutputPanel) styleLine(line string, stderr bool) string {
 	// Command prompt line (starts with $)
 	if strings.HasPrefix(line, "$ ") {
 		prompt := p.styles.OutputPrompt.Render("$ ")
@@ -418,6 +625,11 @@ func (p *OutputPanel) styleLine(line string) string {
 		return prompt + command
 	}
 
+	// Working directory or target line
+	if strings.HasPrefix(line, "📁 ") || strings.HasPrefix(line, "🖥  ") {
+		return p.styles.OutputDuration.Render(line)
+	}
+
 	// Separator line (─────)
 	if strings.HasPrefix(line, "═") || strings.HasPrefix(line, "─") || strings.HasPrefix(line, "━") {
 		return p.styles.OutputSeparator.Render(line)
@@ -433,24 +645,27 @@ func (p *OutputPanel) styleLine(line string) string {
 		return p.styles.OutputExitFail.Render("✗ ") + p.styles.OutputError.Render(line)
 	}
 
+	// Stopped by a timeout or on request
+	if strings.Contains(line, "[Timed out after ") || strings.Contains(line, "[Cancelled]") {
+		return lipgloss.NewStyle().Foreground(theme.CurrentTheme.GetWarning()).Render(line)
+	}
+
 	// Duration line
 	if strings.HasPrefix(line, "[Duration:") {
 		return p.styles.OutputDuration.Render(line)
 	}
 
-	// Error lines
-	if strings.HasPrefix(strings.ToLower(line), "error") ||
-		strings.HasPrefix(strings.ToLower(line), "fatal") ||
-		strings.HasPrefix(strings.ToLower(line), "failed") {
-		return p.styles.OutputError.Render(line)
-	}
-
 	// Warning lines
 	if strings.HasPrefix(strings.ToLower(line), "warning") ||
 		strings.HasPrefix(strings.ToLower(line), "warn") {
 		return lipgloss.NewStyle().Foreground(theme.CurrentTheme.GetWarning()).Render(line)
 	}
 
+	// Error output
+	if stderr {
+		return p.styles.OutputError.Render(line)
+	}
+
 	// Default output text
 	return p.styles.OutputText.Render(line)
 }
@@ -0,0 +1,101 @@
+package ui
+
+import "strings"
+
+// Choice is an entry of a ChoicePicker
+type Choice struct {
+	Name        string
+	Description string
+	Current     bool // Marked as the one in use
+}
+
+// ChoicePicker is a modal list for switching between named settings, such
+// as environment profiles
+type ChoicePicker struct {
+	Title         string
+	Empty         string // Shown when there are no choices
+	Items         []Choice
+	SelectedIndex int
+	Width         int
+	styles        *Styles
+}
+
+// NewChoicePicker creates a new choice picker with the current choice selected
+func NewChoicePicker(styles *Styles, title string, items []Choice) *ChoicePicker {
+	p := &ChoicePicker{
+		Title:  title,
+		Items:  items,
+		Width:  60,
+		styles: styles,
+	}
+	for i, item := range items {
+		if item.Current {
+			p.SelectedIndex = i
+		}
+	}
+	return p
+}
+
+// MoveUp moves selection up
+func (p *ChoicePicker) MoveUp() {
+	if p.SelectedIndex > 0 {
+		p.SelectedIndex--
+	}
+}
+
+// MoveDown moves selection down
+func (p *ChoicePicker) MoveDown() {
+	if p.SelectedIndex < len(p.Items)-1 {
+		p.SelectedIndex++
+	}
+}
+
+// GetSelected returns the index of the selected choice, or -1
+func (p *ChoicePicker) GetSelected() int {
+	if p.SelectedIndex < 0 || p.SelectedIndex >= len(p.Items) {
+		return -1
+	}
+	return p.SelectedIndex
+}
+
+// SetWidth sets the picker width
+func (p *ChoicePicker) SetWidth(width int) {
+	p.Width = width
+}
+
+// SetStyles updates the styles for the picker
+func (p *ChoicePicker) SetStyles(styles *Styles) {
+	p.styles = styles
+}
+
+// View renders the picker
+func (p *ChoicePicker) View() string {
+	innerWidth := p.Width - 4
+	lines := []string{p.styles.ModalTitle.Render(p.Title)}
+
+	if len(p.Items) == 0 {
+		lines = append(lines, p.styles.ModalHint.Render("  "+p.Empty))
+	}
+	for i, item := range p.Items {
+		name := item.Name
+		if item.Current {
+			name += " ✓"
+		}
+		name = truncateString(name, innerWidth-2)
+		if i == p.SelectedIndex {
+			lines = append(lines, p.styles.SuggestionSelected.Render("▶ "+name))
+			if item.Description != "" {
+				lines = append(lines, p.styles.ModalHint.Render("    "+truncateString(item.Description, innerWidth-4)))
+			}
+		} else {
+			lines = append(lines, p.styles.ModalLabel.Render("  "+name))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, p.styles.ModalHint.Render("↑↓: select │ Enter: switch │ Esc: cancel"))
+
+	return p.styles.ModalPanel.
+		Width(p.Width - 2).
+		Render(strings.Join(lines, "\n"))
+}
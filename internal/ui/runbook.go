@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/duladissa/architerm/internal/commands"
+	"github.com/duladissa/architerm/internal/runbook"
+)
+
+// RunbookPicker is a modal list for choosing a runbook to start
+type RunbookPicker struct {
+	Items         []commands.Runbook
+	SelectedIndex int
+	Width         int
+	styles        *Styles
+}
+
+// NewRunbookPicker creates a new runbook picker
+func NewRunbookPicker(styles *Styles, items []commands.Runbook) *RunbookPicker {
+	return &RunbookPicker{
+		Items:         items,
+		SelectedIndex: 0,
+		Width:         60,
+		styles:        styles,
+	}
+}
+
+// MoveUp moves selection up
+func (p *RunbookPicker) MoveUp() {
+	if p.SelectedIndex > 0 {
+		p.SelectedIndex--
+	}
+}
+
+// MoveDown moves selection down
+func (p *RunbookPicker) MoveDown() {
+	if p.SelectedIndex < len(p.Items)-1 {
+		p.SelectedIndex++
+	}
+}
+
+// GetSelected returns the selected runbook
+func (p *RunbookPicker) GetSelected() *commands.Runbook {
+	if p.SelectedIndex < 0 || p.SelectedIndex >= len(p.Items) {
+		return nil
+	}
+	return &p.Items[p.SelectedIndex]
+}
+
+// SetWidth sets the picker width
+func (p *RunbookPicker) SetWidth(width int) {
+	p.Width = width
+}
+
+// SetStyles updates the styles for the picker
+func (p *RunbookPicker) SetStyles(styles *Styles) {
+	p.styles = styles
+}
+
+// View renders the picker
+func (p *RunbookPicker) View() string {
+	innerWidth := p.Width - 4
+	lines := []string{p.styles.ModalTitle.Render("📒 Runbooks")}
+
+	if len(p.Items) == 0 {
+		lines = append(lines, p.styles.ModalHint.Render("  No runbooks defined. Add a \"runbooks\" section to your config."))
+	}
+	for i, rb := range p.Items {
+		name := truncateString(fmt.Sprintf("%s (%d steps)", rb.Name, len(rb.Steps)), innerWidth-2)
+		if i == p.SelectedIndex {
+			lines = append(lines, p.styles.SuggestionSelected.Render("▶ "+name))
+			if rb.Description != "" {
+				lines = append(lines, p.styles.ModalHint.Render("    "+truncateString(rb.Description, innerWidth-4)))
+			}
+		} else {
+			lines = append(lines, p.styles.ModalLabel.Render("  "+name))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, p.styles.ModalHint.Render("↑↓: select │ Enter: start │ Esc: cancel"))
+
+	return p.styles.ModalPanel.
+		Width(p.Width - 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+// RunbookPanel shows the step status of the active runbook
+type RunbookPanel struct {
+	Run    *runbook.Run
+	Width  int
+	Height int
+	styles *Styles
+}
+
+// NewRunbookPanel creates a new runbook panel
+func NewRunbookPanel(styles *Styles) *RunbookPanel {
+	return &RunbookPanel{
+		Width:  80,
+		Height: 15,
+		styles: styles,
+	}
+}
+
+// SetRun sets the runbook execution to display
+func (p *RunbookPanel) SetRun(run *runbook.Run) {
+	p.Run = run
+}
+
+// SetWidth sets the panel width
+func (p *RunbookPanel) SetWidth(width int) {
+	p.Width = width
+}
+
+// SetHeight sets the panel height
+func (p *RunbookPanel) SetHeight(height int) {
+	p.Height = height
+}
+
+// SetStyles updates the styles for the runbook panel
+func (p *RunbookPanel) SetStyles(styles *Styles) {
+	p.styles = styles
+}
+
+// View renders the runbook panel
+func (p *RunbookPanel) View() string {
+	if p.Run == nil {
+		return ""
+	}
+	run := p.Run
+	innerWidth := p.Width - 6
+
+	title := "📒 " + run.Runbook.Name
+	switch {
+	case run.Done():
+		title += " (finished)"
+	case run.Paused:
+		title += " (paused)"
+	}
+	titleText := p.styles.SuggestionsPanelTitle.Render(truncateString(title, innerWidth))
+
+	var lines []string
+	for i, step := range run.Runbook.Steps {
+		marker := "  "
+		if i == run.Current && !run.Done() {
+			marker = "▶ "
+		}
+		name := step.Name
+		if name == "" {
+			name = step.Command
+		}
+		text := truncateString(fmt.Sprintf("%s%s %d. %s", marker, stepIcon(run.Status[i]), i+1, name), innerWidth)
+		lines = append(lines, p.stepStyle(run.Status[i]).Render(text))
+		if run.Errors[i] != "" {
+			lines = append(lines, p.styles.OutputError.Render(truncateString("      "+run.Errors[i], innerWidth)))
+		}
+	}
+
+	if len(run.Vars) > 0 {
+		names := make([]string, 0, len(run.Vars))
+		for name := range run.Vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		pairs := make([]string, len(names))
+		for i, name := range names {
+			pairs[i] = name + "=" + run.Vars[name]
+		}
+		lines = append(lines, "")
+		lines = append(lines, p.styles.SuggestionDesc.Render(truncateString("  "+strings.Join(pairs, " "), innerWidth)))
+	}
+
+	// Keep the hint line at the bottom of the panel
+	availableLines := p.Height - 4
+	if len(lines) > availableLines {
+		lines = lines[len(lines)-availableLines:]
+	}
+	for len(lines) < availableLines {
+		lines = append(lines, "")
+	}
+	hint := "p: pause │ s: skip │ r: retry │ Esc: close"
+	if run.Done() {
+		hint = "Esc: close"
+	}
+	lines = append(lines, p.styles.StatusKeyHint.Render(truncateString(hint, innerWidth)))
+
+	return p.styles.SuggestionsPanel.
+		Width(p.Width - 2).
+		Height(p.Height).
+		BorderTop(true).
+		BorderLeft(true).
+		BorderRight(true).
+		BorderBottom(true).
+		Render(titleText + "\n" + strings.Join(lines, "\n"))
+}
+
+// stepStyle returns the text style for a step status
+func (p *RunbookPanel) stepStyle(status runbook.StepStatus) lipgloss.Style {
+	switch status {
+	case runbook.StepRunning:
+		return p.styles.OutputCommand
+	case runbook.StepOK:
+		return p.styles.OutputExitOK
+	case runbook.StepFailed:
+		return p.styles.OutputExitFail
+	case runbook.StepSkipped:
+		return p.styles.SuggestionDesc
+	default:
+		return p.styles.OutputText
+	}
+}
+
+// stepIcon returns the status icon for a step
+func stepIcon(status runbook.StepStatus) string {
+	switch status {
+	case runbook.StepRunning:
+		return "⏳"
+	case runbook.StepOK:
+		return "✓"
+	case runbook.StepFailed:
+		return "✗"
+	case runbook.StepSkipped:
+		return "↷"
+	default:
+		return "○"
+	}
+}
@@ -36,6 +36,8 @@ type Styles struct {
 	InputText        lipgloss.Style
 	InputGhost       lipgloss.Style
 	InputCursor      lipgloss.Style
+	InputPlaceholder lipgloss.Style
+	InputSelection   lipgloss.Style
 
 	// Suggestions panel
 	SuggestionsPanel      lipgloss.Style
@@ -59,10 +61,22 @@ type Styles struct {
 	OutputExitOK     lipgloss.Style
 	OutputExitFail   lipgloss.Style
 
+	// Modal dialogs (placeholder form)
+	ModalPanel      lipgloss.Style
+	ModalTitle      lipgloss.Style
+	ModalLabel      lipgloss.Style
+	ModalLabelFocus lipgloss.Style
+	ModalInput      lipgloss.Style
+	ModalHint       lipgloss.Style
+	ModalError      lipgloss.Style
+	SearchMatch     lipgloss.Style
+
 	// Status bar
-	StatusBar     lipgloss.Style
-	StatusText    lipgloss.Style
-	StatusKeyHint lipgloss.Style
+	StatusBar       lipgloss.Style
+	StatusText      lipgloss.Style
+	StatusKeyHint   lipgloss.Style
+	StatusProfile   lipgloss.Style
+	StatusProtected lipgloss.Style
 
 	// General
 	Border lipgloss.Style
@@ -100,6 +114,8 @@ func DefaultStyles() *Styles {
 	s.InputText = lipgloss.NewStyle().Foreground(t.GetForeground()).Background(t.GetBackground())
 	s.InputGhost = lipgloss.NewStyle().Foreground(t.GetMuted()).Background(t.GetBackground()).Italic(true)
 	s.InputCursor = lipgloss.NewStyle().Foreground(t.GetForeground()).Background(t.GetSuggestionMatch())
+	s.InputPlaceholder = lipgloss.NewStyle().Foreground(t.GetWarning()).Background(t.GetBackground()).Underline(true)
+	s.InputSelection = lipgloss.NewStyle().Foreground(t.GetSelectionFg()).Background(t.GetSelectionBg()).Bold(true)
 
 	// Suggestions panel
 	s.SuggestionsPanel = baseBorder.Copy().BorderForeground(t.GetSecondary()).Padding(0, 1)
@@ -123,10 +139,22 @@ func DefaultStyles() *Styles {
 	s.OutputExitOK = lipgloss.NewStyle().Foreground(t.GetSuccess()).Background(t.GetBackground()).Bold(true)
 	s.OutputExitFail = lipgloss.NewStyle().Foreground(t.GetError()).Background(t.GetBackground()).Bold(true)
 
+	// Modal dialogs
+	s.ModalPanel = baseBorder.Copy().BorderStyle(lipgloss.DoubleBorder()).BorderForeground(t.GetPrimary()).Padding(0, 1)
+	s.ModalTitle = lipgloss.NewStyle().Foreground(t.GetPrimary()).Background(t.GetBackground()).Bold(true)
+	s.ModalLabel = lipgloss.NewStyle().Foreground(t.GetForeground()).Background(t.GetBackground())
+	s.ModalLabelFocus = lipgloss.NewStyle().Foreground(t.GetSecondary()).Background(t.GetBackground()).Bold(true)
+	s.ModalInput = lipgloss.NewStyle().Foreground(t.GetCommand()).Background(t.GetBackground())
+	s.ModalHint = lipgloss.NewStyle().Foreground(t.GetMuted()).Background(t.GetBackground()).Italic(true)
+	s.ModalError = lipgloss.NewStyle().Foreground(t.GetError()).Background(t.GetBackground())
+	s.SearchMatch = lipgloss.NewStyle().Foreground(t.GetSuggestionMatch()).Background(t.GetBackground()).Bold(true).Underline(true)
+
 	// Status bar
 	s.StatusBar = lipgloss.NewStyle().Foreground(t.GetMuted()).Background(t.GetBackground()).Padding(0, 1)
 	s.StatusText = lipgloss.NewStyle().Foreground(t.GetMuted()).Background(t.GetBackground())
 	s.StatusKeyHint = lipgloss.NewStyle().Foreground(t.GetSecondary()).Background(t.GetBackground()).Bold(true)
+	s.StatusProfile = lipgloss.NewStyle().Foreground(t.GetAccent()).Background(t.GetBackground()).Bold(true)
+	s.StatusProtected = lipgloss.NewStyle().Foreground(t.GetBackground()).Background(t.GetError()).Bold(true)
 
 	return s
 }
@@ -60,6 +60,21 @@ func (p *SuggestionsPanel) MoveDown() {
 	}
 }
 
+// Select selects the item with the given command, if present
+func (p *SuggestionsPanel) Select(command string) {
+	for i, item := range p.Items {
+		if item.Command == command {
+			p.SelectedIndex = i
+			if i < p.ScrollOffset {
+				p.ScrollOffset = i
+			} else if i >= p.ScrollOffset+p.MaxVisible {
+				p.ScrollOffset = i - p.MaxVisible + 1
+			}
+			return
+		}
+	}
+}
+
 // GetSelected returns the currently selected item
 func (p *SuggestionsPanel) GetSelected() *autocomplete.Match {
 	if len(p.Items) == 0 || p.SelectedIndex >= len(p.Items) {
@@ -114,6 +129,9 @@ func (p *SuggestionsPanel) View() string {
 			// Format command and description
 			cmd := truncateString(item.Command, p.Width-30)
 			desc := truncateString(item.Description, 20)
+			if item.Favorite {
+				cmd = "★ " + truncateString(item.Command, p.Width-32)
+			}
 			
 			if i == p.SelectedIndex {
 				line := p.styles.SuggestionSelected.Render(fmt.Sprintf("▶ %s", cmd))
@@ -0,0 +1,23 @@
+//go:build unix
+
+package vterm
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// closeGroup hangs up the process group of cmd, which pty made the leader of
+// a new session, as closing a terminal window does. Whatever is still running
+// after grace, such as children ignoring the hangup, is killed.
+func closeGroup(cmd *exec.Cmd, grace time.Duration) error {
+	pid := cmd.Process.Pid
+	if err := syscall.Kill(-pid, syscall.SIGHUP); err != nil {
+		return cmd.Process.Kill()
+	}
+	time.AfterFunc(grace, func() {
+		syscall.Kill(-pid, syscall.SIGKILL)
+	})
+	return nil
+}
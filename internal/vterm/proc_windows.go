@@ -0,0 +1,13 @@
+//go:build windows
+
+package vterm
+
+import (
+	"os/exec"
+	"time"
+)
+
+// closeGroup kills the command; Windows has no hangup to send first
+func closeGroup(cmd *exec.Cmd, grace time.Duration) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,87 @@
+package vterm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hinshun/vt10x"
+)
+
+// Glyph attributes set by the emulator
+const (
+	attrReverse = 1 << iota
+	attrUnderline
+	attrBold
+	attrGfx
+	attrItalic
+	attrBlink
+)
+
+// Lines renders the screen, one string per row. With cursor set, the cursor
+// cell is shown in reverse video.
+func (s *Session) Lines(cursor bool) []string {
+	s.term.Lock()
+	defer s.term.Unlock()
+
+	cols, rows := s.term.Size()
+	cur := s.term.Cursor()
+	showCursor := cursor && s.term.CursorVisible()
+
+	lines := make([]string, rows)
+	for y := 0; y < rows; y++ {
+		var sb strings.Builder
+		var run strings.Builder
+		var runGlyph vt10x.Glyph
+		flush := func() {
+			if run.Len() > 0 {
+				sb.WriteString(glyphStyle(runGlyph).Render(run.String()))
+				run.Reset()
+			}
+		}
+		for x := 0; x < cols; x++ {
+			glyph := s.term.Cell(x, y)
+			if glyph.Char == 0 {
+				glyph.Char = ' '
+			}
+			if showCursor && x == cur.X && y == cur.Y {
+				glyph.Mode ^= attrReverse
+			}
+			if run.Len() > 0 && (glyph.FG != runGlyph.FG || glyph.BG != runGlyph.BG || glyph.Mode != runGlyph.Mode) {
+				flush()
+			}
+			runGlyph = glyph
+			run.WriteRune(glyph.Char)
+		}
+		flush()
+		lines[y] = sb.String()
+	}
+	return lines
+}
+
+// glyphStyle returns the style drawing a glyph's colors and attributes
+func glyphStyle(glyph vt10x.Glyph) lipgloss.Style {
+	style := lipgloss.NewStyle()
+	if glyph.FG < 256 {
+		style = style.Foreground(lipgloss.Color(strconv.Itoa(int(glyph.FG))))
+	} else if glyph.FG < vt10x.DefaultFG {
+		style = style.Foreground(lipgloss.Color(trueColor(glyph.FG)))
+	}
+	if glyph.BG < 256 {
+		style = style.Background(lipgloss.Color(strconv.Itoa(int(glyph.BG))))
+	} else if glyph.BG < vt10x.DefaultFG {
+		style = style.Background(lipgloss.Color(trueColor(glyph.BG)))
+	}
+	return style.
+		Reverse(glyph.Mode&attrReverse != 0).
+		Underline(glyph.Mode&attrUnderline != 0).
+		Bold(glyph.Mode&attrBold != 0).
+		Italic(glyph.Mode&attrItalic != 0).
+		Blink(glyph.Mode&attrBlink != 0)
+}
+
+// trueColor formats a 24-bit color as #rrggbb
+func trueColor(c vt10x.Color) string {
+	hex := strconv.FormatUint(uint64(c)&0xffffff, 16)
+	return "#" + strings.Repeat("0", 6-len(hex)) + hex
+}
@@ -0,0 +1,196 @@
+package vterm
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/creack/pty"
+	"github.com/duladissa/architerm/internal/executor"
+	"github.com/hinshun/vt10x"
+)
+
+// Session is a command running in a pseudo-terminal. Its output is
+// interpreted by a virtual terminal emulator so the screen can be drawn
+// inside the TUI, and key presses are written back to it.
+type Session struct {
+	Command string
+
+	opts    executor.Options
+	cmd     *exec.Cmd
+	pty     *os.File
+	term    vt10x.Terminal
+	updates chan struct{}
+
+	mu     sync.Mutex
+	result *executor.Result
+}
+
+// Start runs command in the system shell on a new pseudo-terminal of the
+// given size
+func Start(command string, opts executor.Options, cols, rows int) (*Session, error) {
+	cols, rows = clampSize(cols, rows)
+	cmd := executor.InteractiveCommand(command, opts)
+	env := opts.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(slices.Clip(env), "TERM=xterm-256color")
+
+	start := time.Now()
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{
+		Command: command,
+		opts:    opts,
+		cmd:     cmd,
+		pty:     ptmx,
+		// Replies to terminal queries (cursor position, device attributes) go back to the command
+		term:    vt10x.New(vt10x.WithWriter(ptmx), vt10x.WithSize(cols, rows)),
+		updates: make(chan struct{}, 1),
+	}
+	go s.read(start)
+	return s, nil
+}
+
+// read feeds the command's output to the emulator until it exits
+func (s *Session) read(start time.Time) {
+	buf := make([]byte, 32*1024)
+	var pending []byte
+	for {
+		n, err := s.pty.Read(buf)
+		if n > 0 {
+			// Only pass whole characters; a split one is completed by the next read
+			pending = append(pending, buf[:n]...)
+			complete := completeRunes(pending)
+			s.term.Write(pending[:complete])
+			pending = append(pending[:0], pending[complete:]...)
+			s.notify()
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	err := s.cmd.Wait()
+	s.pty.Close()
+	result := executor.InteractiveResult(s.Command, s.opts, start, err)
+	if text := s.Text(); text != "" {
+		result.Output = text
+	}
+
+	s.mu.Lock()
+	s.result = result
+	s.mu.Unlock()
+	close(s.updates)
+}
+
+// notify signals an update without blocking; one pending signal is enough
+// for the screen to be redrawn
+func (s *Session) notify() {
+	select {
+	case s.updates <- struct{}{}:
+	default:
+	}
+}
+
+// Updates returns a channel signalled when the screen changes and closed
+// when the command has exited
+func (s *Session) Updates() <-chan struct{} {
+	return s.updates
+}
+
+// Result returns the result of the command, or nil while it is running
+func (s *Session) Result() *executor.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.result
+}
+
+// Write sends input, such as key presses, to the command
+func (s *Session) Write(p []byte) error {
+	_, err := s.pty.Write(p)
+	return err
+}
+
+// Resize changes the size of the terminal
+func (s *Session) Resize(cols, rows int) {
+	cols, rows = clampSize(cols, rows)
+	s.term.Lock()
+	c, r := s.term.Size()
+	s.term.Unlock()
+	if c == cols && r == rows {
+		return
+	}
+	s.term.Resize(cols, rows)
+	pty.Setsize(s.pty, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
+
+// AppCursor reports whether the command asked for application cursor keys,
+// which changes the sequences sent for the arrow keys
+func (s *Session) AppCursor() bool {
+	s.term.Lock()
+	defer s.term.Unlock()
+	return s.term.Mode()&vt10x.ModeAppCursor != 0
+}
+
+// Close hangs up the command and its children, killing those still running
+// after the terminate grace period
+func (s *Session) Close() error {
+	if s.cmd.Process == nil {
+		return errors.New("not started")
+	}
+	grace := s.opts.TerminateGrace
+	if grace <= 0 {
+		grace = executor.DefaultTerminateGrace
+	}
+	return closeGroup(s.cmd, grace)
+}
+
+// Text returns the screen contents without trailing blank lines or spaces
+func (s *Session) Text() string {
+	lines := strings.Split(s.term.String(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(strings.ReplaceAll(lines[i], "\x00", " "), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// completeRunes returns the length of the longest prefix of p that does not
+// end in an incomplete UTF-8 sequence
+func completeRunes(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
+
+// clampSize keeps the terminal at least a few cells large
+func clampSize(cols, rows int) (int, int) {
+	if cols < 10 {
+		cols = 10
+	}
+	if rows < 2 {
+		rows = 2
+	}
+	return cols, rows
+}